@@ -0,0 +1,30 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Hub marks v1 as the version the other IAFDemo versions are converted to and from.
+// v1 is also the storage version, so the controller only ever works with v1 objects.
+func (*IAFDemo) Hub() {}
@@ -27,7 +27,9 @@ package v1
 
 import (
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/commoncrd"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -36,26 +38,639 @@ import (
 // IAFDemoSpec defines the desired state of IAFDemo
 type IAFDemoSpec struct {
 	// Number of messages to put on Kafka topic all at once
+	// +kubebuilder:validation:Pattern=`^[0-9]*$`
 	MessagesPerGroup string `json:"messagesPerGroup,omitempty"`
 
 	// Number of seconds to pause between groups of Kafka messages
+	// +kubebuilder:validation:Pattern=`^[0-9]*$`
 	SecondsToPause string `json:"secondsToPause,omitempty"`
 
 	// Number of times to submit the 1725 rows of sample data. Default is 1; '-1' means keep submitting forever.
+	// +kubebuilder:validation:Pattern=`^(-1|[0-9]*)$`
 	SequenceRepititions string `json:"sequenceRepititions,omitempty"`
 
+	// Switches for the optional parts of the demo
+	// +optional
+	Components IAFDemoComponents `json:"components,omitempty"`
+
+	// Settings of the Kafka topics of the demo
+	// +optional
+	Topics IAFDemoTopics `json:"topics,omitempty"`
+
+	// How the anomalies are delivered to the server when spec.components.knative is on
+	// +optional
+	Knative IAFDemoKnative `json:"knative,omitempty"`
+
+	// Where the AI models are stored for KFServing to load them from
+	// +optional
+	ModelStore ModelStore `json:"modelStore,omitempty"`
+
+	// The AI models to deploy, each with an AIModel and an AIDeployment. The EventProcessingTask scores events
+	// with the first one. Default is the anomaly-classifier model bundled with the operator.
+	// +optional
+	Models []ModelSpec `json:"models,omitempty"`
+
+	// What to do with the Elasticsearch indices and the AI models uploaded to MinIO when the IAFDemo is deleted.
+	// Default is Retain.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// By installing this component you accept the license terms http://ibm.biz/IAF-license
 	License commoncrd.License `json:"license"`
 }
 
+// IAFDemoComponents switches the optional parts of the demo on or off. Turning a component off removes
+// what the operator created for it.
+type IAFDemoComponents struct {
+	// Score events with the AI model served by KFServing. Default is true.
+	// +optional
+	AI *bool `json:"ai,omitempty"`
+
+	// Deliver anomalies to the server through Knative. Default is the USE_KNATIVE setting of the operator.
+	// +optional
+	Knative *bool `json:"knative,omitempty"`
+
+	// Index raw events and anomalies in Elasticsearch. Default is true.
+	// +optional
+	Elasticsearch *bool `json:"elasticsearch,omitempty"`
+
+	// Run the producer that sends the sample data to Kafka. Default is true.
+	// +optional
+	Producer *bool `json:"producer,omitempty"`
+
+	// Run the server that receives the anomalies. Default is true.
+	// +optional
+	Server *bool `json:"server,omitempty"`
+
+	// Send high-risk anomalies to a separate alerts topic as well. Default is false.
+	// +optional
+	Alerts *bool `json:"alerts,omitempty"`
+}
+
+// IAFDemoTopics overrides the settings of the Kafka topics of the demo. Changing them patches the existing KafkaTopics.
+type IAFDemoTopics struct {
+	// The topic the producer sends the sample data to
+	// +optional
+	Raw KafkaTopicSettings `json:"raw,omitempty"`
+
+	// The topic the Flink job sends the anomalies to
+	// +optional
+	Anomaly KafkaTopicSettings `json:"anomaly,omitempty"`
+
+	// The topic malformed events are sent to, so they can be inspected without stopping the pipeline
+	// +optional
+	DeadLetter KafkaTopicSettings `json:"deadLetter,omitempty"`
+
+	// The topic the Flink job sends the high-risk anomalies to, when spec.components.alerts is on
+	// +optional
+	Alerts KafkaTopicSettings `json:"alerts,omitempty"`
+}
+
+// KafkaTopicSettings overrides the defaults of one KafkaTopic
+type KafkaTopicSettings struct {
+	// Number of partitions of the topic. Default is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Partitions *int32 `json:"partitions,omitempty"`
+
+	// Number of replicas of the topic. Default is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32767
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Kafka topic configs, merged over the defaults: a retention.ms of 7 days and a segment.bytes of 1 GiB
+	// +optional
+	Config map[string]intstr.IntOrString `json:"config,omitempty"`
+
+	// The Event Streams cluster the topic belongs to. Default is iaf-system.
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// PartitionsOrDefault returns the partitions of the topic, or DefaultTopicPartitions if they are not set
+func (s KafkaTopicSettings) PartitionsOrDefault() int32 {
+	if s.Partitions == nil {
+		return DefaultTopicPartitions
+	}
+	return *s.Partitions
+}
+
+// ReplicasOrDefault returns the replicas of the topic, or DefaultTopicReplicas if they are not set
+func (s KafkaTopicSettings) ReplicasOrDefault() int32 {
+	if s.Replicas == nil {
+		return DefaultTopicReplicas
+	}
+	return *s.Replicas
+}
+
+// ClusterOrDefault returns the Event Streams cluster of the topic, or DefaultKafkaCluster if it is not set
+func (s KafkaTopicSettings) ClusterOrDefault() string {
+	if s.Cluster == "" {
+		return DefaultKafkaCluster
+	}
+	return s.Cluster
+}
+
+// IAFDemoKnative selects the Knative topology that delivers the anomalies from Kafka to the server
+type IAFDemoKnative struct {
+	// Deliver through a channel and a Subscription, or through a Broker and a Trigger. Default is Channel.
+	// +optional
+	Delivery KnativeDelivery `json:"delivery,omitempty"`
+
+	// The kind of channel used by Channel delivery. Default is InMemoryChannel.
+	// +optional
+	ChannelKind KnativeChannelKind `json:"channelKind,omitempty"`
+
+	// The class of the Broker used by Broker delivery, for example Kafka for a Kafka-backed broker.
+	// Default is MTChannelBasedBroker.
+	// +optional
+	BrokerClass string `json:"brokerClass,omitempty"`
+
+	// The Kafka listener the KafkaSource reads from: TLS, authenticating as the KafkaUser of the IAFDemo,
+	// or Plain, without authentication. Default is TLS.
+	// +optional
+	Listener KafkaListener `json:"listener,omitempty"`
+
+	// CloudEvent attributes, such as type or source, that an anomaly must match to reach the server with
+	// Broker delivery. An empty value matches any value. Default is to deliver every anomaly. With the alerts
+	// topic on, a source in the filter also has the high-risk anomalies of the alerts topic sent to the Broker.
+	// +optional
+	Filter map[string]string `json:"filter,omitempty"`
+
+	// How the server is deployed while Knative is enabled: a Deployment with a Service and Route, or a
+	// Knative Serving Service that scales to zero when idle. Default is Deployment.
+	// +optional
+	ServerKind KnativeServerKind `json:"serverKind,omitempty"`
+}
+
+// KnativeDelivery is the Knative topology between the KafkaSource and the server
+// +kubebuilder:validation:Enum=Channel;Broker
+type KnativeDelivery string
+
+const (
+	// KnativeDeliveryChannel sends the anomalies through a channel, and a Subscription delivers them to the server
+	KnativeDeliveryChannel KnativeDelivery = "Channel"
+	// KnativeDeliveryBroker sends the anomalies to a Broker, and a Trigger delivers those that pass its filter to the server
+	KnativeDeliveryBroker KnativeDelivery = "Broker"
+)
+
+// KafkaListener is the Kafka listener of Event Streams a client connects to
+// +kubebuilder:validation:Enum=TLS;Plain
+type KafkaListener string
+
+const (
+	// KafkaListenerTLS is the internal-service-tls listener, which requires SCRAM authentication
+	KafkaListenerTLS KafkaListener = "TLS"
+	// KafkaListenerPlain is the internal-service-plain listener, which hardened clusters disable
+	KafkaListenerPlain KafkaListener = "Plain"
+)
+
+// KnativeChannelKind is the kind of channel used by Channel delivery
+// +kubebuilder:validation:Enum=InMemoryChannel;KafkaChannel
+type KnativeChannelKind string
+
+const (
+	// KnativeChannelInMemory keeps the anomalies in memory in the channel dispatcher
+	KnativeChannelInMemory KnativeChannelKind = "InMemoryChannel"
+	// KnativeChannelKafka keeps the anomalies in a Kafka topic of the channel, which requires the KafkaChannel CRD
+	KnativeChannelKafka KnativeChannelKind = "KafkaChannel"
+)
+
+// KnativeServerKind is how the server is deployed while Knative is enabled
+// +kubebuilder:validation:Enum=Deployment;KnativeService
+type KnativeServerKind string
+
+const (
+	// KnativeServerDeployment runs the server as a Deployment, exposed by a Service and a Route
+	KnativeServerDeployment KnativeServerKind = "Deployment"
+	// KnativeServerService runs the server as a Knative Serving Service, which requires Knative Serving
+	KnativeServerService KnativeServerKind = "KnativeService"
+)
+
+// DeliveryOrDefault returns the Knative delivery, or DefaultKnativeDelivery if it is not set
+func (k IAFDemoKnative) DeliveryOrDefault() KnativeDelivery {
+	if k.Delivery == "" {
+		return DefaultKnativeDelivery
+	}
+	return k.Delivery
+}
+
+// ChannelKindOrDefault returns the channel kind, or DefaultKnativeChannelKind if it is not set
+func (k IAFDemoKnative) ChannelKindOrDefault() KnativeChannelKind {
+	if k.ChannelKind == "" {
+		return DefaultKnativeChannelKind
+	}
+	return k.ChannelKind
+}
+
+// ListenerOrDefault returns the Kafka listener, or DefaultKnativeListener if it is not set
+func (k IAFDemoKnative) ListenerOrDefault() KafkaListener {
+	if k.Listener == "" {
+		return DefaultKnativeListener
+	}
+	return k.Listener
+}
+
+// BrokerClassOrDefault returns the Broker class, or DefaultKnativeBrokerClass if it is not set
+func (k IAFDemoKnative) BrokerClassOrDefault() string {
+	if k.BrokerClass == "" {
+		return DefaultKnativeBrokerClass
+	}
+	return k.BrokerClass
+}
+
+// ServerKindOrDefault returns how the server is deployed, or DefaultKnativeServerKind if it is not set
+func (k IAFDemoKnative) ServerKindOrDefault() KnativeServerKind {
+	if k.ServerKind == "" {
+		return DefaultKnativeServerKind
+	}
+	return k.ServerKind
+}
+
+// ModelSpec describes an AI model to serve with KFServing
+type ModelSpec struct {
+	// The name of the model, which is also the name of its AIModel, shared by the IAFDemos in the namespace.
+	// The AI operator loads the model from models/ followed by this name in the model store. Its AIDeployment
+	// is named after the IAFDemo and the model.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=22
+	Name string `json:"name"`
+
+	// The framework the model was built with, such as tensorflow. Default is tensorflow.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// The version of the model. Default is 1.0.0.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// The AIRuntime that serves the model. The operator creates the default, kfservingruntime; any other
+	// AIRuntime must already exist. Default is kfservingruntime.
+	// +optional
+	Runtime string `json:"runtime,omitempty"`
+
+	// A description of the model
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// A candidate version of the model, served by its own AIDeployment next to this one and scoring a share
+	// of the events until it is promoted or rolled back
+	// +optional
+	Canary ModelCanary `json:"canary,omitempty"`
+}
+
+// ModelCanary is a candidate version of a model that is rolled out gradually
+type ModelCanary struct {
+	// The version of the candidate. Setting it starts the rollout.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// The name of the model that holds the candidate, which the AI operator loads from models/ followed by
+	// this name in the model store. It must differ from the name of the model. Default is the name of the
+	// model followed by -canary.
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// The percentage of the events scored by the candidate while it is a canary. Default is 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	TrafficPercent *int32 `json:"trafficPercent,omitempty"`
+
+	// The stage of the rollout. Canary splits the events between both versions, Promote serves only the
+	// candidate from the AIDeployment of the model, and Rollback serves only the current version.
+	// Default is Canary.
+	// +optional
+	Rollout ModelRollout `json:"rollout,omitempty"`
+}
+
+// ModelRollout is the stage of the rollout of a candidate model version
+// +kubebuilder:validation:Enum=Canary;Promote;Rollback
+type ModelRollout string
+
+const (
+	// ModelRolloutCanary serves both versions, each with its share of the events
+	ModelRolloutCanary ModelRollout = "Canary"
+	// ModelRolloutPromote replaces the current version with the candidate
+	ModelRolloutPromote ModelRollout = "Promote"
+	// ModelRolloutRollback removes the candidate and keeps the current version
+	ModelRolloutRollback ModelRollout = "Rollback"
+)
+
+// DeletionPolicyOrDefault returns the deletion policy, or DefaultDeletionPolicy if it is not set
+func (s *IAFDemoSpec) DeletionPolicyOrDefault() DeletionPolicy {
+	if s.DeletionPolicy == "" {
+		return DefaultDeletionPolicy
+	}
+	return s.DeletionPolicy
+}
+
+// ModelsOrDefault returns the models to deploy, or DefaultModel if none are listed
+func (s *IAFDemoSpec) ModelsOrDefault() []ModelSpec {
+	if len(s.Models) == 0 {
+		return []ModelSpec{DefaultModel}
+	}
+	return s.Models
+}
+
+// TypeOrDefault returns the framework of the model, or DefaultModelType if it is not set
+func (m ModelSpec) TypeOrDefault() string {
+	if m.Type == "" {
+		return DefaultModelType
+	}
+	return m.Type
+}
+
+// VersionOrDefault returns the version of the model, or DefaultModelVersion if it is not set
+func (m ModelSpec) VersionOrDefault() string {
+	if m.Version == "" {
+		return DefaultModelVersion
+	}
+	return m.Version
+}
+
+// CanaryActive reports whether a candidate version is served next to the current one
+func (m ModelSpec) CanaryActive() bool {
+	return m.Canary.Version != "" && m.Canary.RolloutOrDefault() == ModelRolloutCanary
+}
+
+// ServedVersion returns the version served by the AIDeployment of the model, which is the candidate once promoted
+func (m ModelSpec) ServedVersion() string {
+	if m.Canary.Version != "" && m.Canary.RolloutOrDefault() == ModelRolloutPromote {
+		return m.Canary.Version
+	}
+	return m.VersionOrDefault()
+}
+
+// ServedModel returns the name of the stored model served by the AIDeployment of the model, which is the
+// candidate once promoted
+func (m ModelSpec) ServedModel() string {
+	if m.Canary.Version != "" && m.Canary.RolloutOrDefault() == ModelRolloutPromote {
+		return m.CanaryModelOrDefault()
+	}
+	return m.Name
+}
+
+// CanaryModelOrDefault returns the name of the stored model that holds the candidate, or the name of the model
+// followed by CanaryNameSuffix if it is not set
+func (m ModelSpec) CanaryModelOrDefault() string {
+	if m.Canary.Model == "" {
+		return m.Name + CanaryNameSuffix
+	}
+	return m.Canary.Model
+}
+
+// TrafficPercentOrDefault returns the share of the events scored by the candidate, or DefaultCanaryTrafficPercent
+func (c ModelCanary) TrafficPercentOrDefault() int32 {
+	if c.TrafficPercent == nil {
+		return DefaultCanaryTrafficPercent
+	}
+	return *c.TrafficPercent
+}
+
+// RolloutOrDefault returns the stage of the rollout, or DefaultModelRollout if it is not set
+func (c ModelCanary) RolloutOrDefault() ModelRollout {
+	if c.Rollout == "" {
+		return DefaultModelRollout
+	}
+	return c.Rollout
+}
+
+// RuntimeOrDefault returns the AIRuntime that serves the model, or DefaultModelRuntime if it is not set
+func (m ModelSpec) RuntimeOrDefault() string {
+	if m.Runtime == "" {
+		return DefaultModelRuntime
+	}
+	return m.Runtime
+}
+
+// ModelStore selects where the AI models are stored for KFServing to load them from
+type ModelStore struct {
+	// S3 uploads the models bundled with the operator to an S3 or MinIO bucket, which is the only store the
+	// AI operator loads models from. Default is S3.
+	// +optional
+	Type ModelStoreType `json:"type,omitempty"`
+
+	// Delete the objects in the directories of the bundled models in the S3 bucket that are not bundled any
+	// more, such as the files of an older model version. Other models and the candidates listed in spec.models
+	// of any IAFDemo in the namespace are kept. Default is false.
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+
+	// Settings of the S3 store
+	// +optional
+	S3 S3ModelStore `json:"s3,omitempty"`
+}
+
+// ModelStoreType is the kind of storage the AI models are kept in
+// +kubebuilder:validation:Enum=S3
+type ModelStoreType string
+
+const (
+	// ModelStoreS3 keeps the models in a bucket of an S3 compatible object store, such as MinIO
+	ModelStoreS3 ModelStoreType = "S3"
+)
+
+// S3ModelStore is an S3 compatible object store the operator uploads the models to
+type S3ModelStore struct {
+	// The Secret with the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the store, and its endpoint in the
+	// serving.kubeflow.org/s3-endpoint annotation. KFServing reads the models with it too. Default is minio-secret.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// The bucket the models are uploaded to. Default is iaf-ai.
+	// +optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// Connect to the endpoint with TLS. Default is the serving.kubeflow.org/s3-usehttps annotation of the Secret.
+	// +optional
+	TLS *bool `json:"tls,omitempty"`
+
+	// The key of a Secret holding the PEM certificates of the CA that signed the endpoint certificate,
+	// when it is not signed by a well-known CA
+	// +optional
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+}
+
+// TypeOrDefault returns the type of the model store, or DefaultModelStoreType if it is not set
+func (m ModelStore) TypeOrDefault() ModelStoreType {
+	if m.Type == "" {
+		return DefaultModelStoreType
+	}
+	return m.Type
+}
+
+// SecretNameOrDefault returns the Secret of the S3 store, or DefaultModelStoreSecret if it is not set
+func (s S3ModelStore) SecretNameOrDefault() string {
+	if s.SecretName == "" {
+		return DefaultModelStoreSecret
+	}
+	return s.SecretName
+}
+
+// BucketOrDefault returns the bucket of the S3 store, or DefaultModelStoreBucket if it is not set
+func (s S3ModelStore) BucketOrDefault() string {
+	if s.Bucket == "" {
+		return DefaultModelStoreBucket
+	}
+	return s.Bucket
+}
+
+// DeletionPolicy says what happens to the state kept outside of Kubernetes when an IAFDemo is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain leaves the Elasticsearch indices and the uploaded models in place
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete removes the Elasticsearch indices and the uploaded models along with the IAFDemo
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// IAFDemoPhase summarises how far the reconcile of an IAFDemo has got
+type IAFDemoPhase string
+
+const (
+	// PhasePending means the IAFDemo has not been reconciled yet
+	PhasePending IAFDemoPhase = "Pending"
+	// PhaseInstalling means the reconciler is waiting on one of the stages to become Ready
+	PhaseInstalling IAFDemoPhase = "Installing"
+	// PhaseReady means every enabled stage of the demo pipeline is Ready
+	PhaseReady IAFDemoPhase = "Ready"
+	// PhaseDegraded means the demo runs, but a stage it can do without, such as Knative delivery, has failed
+	PhaseDegraded IAFDemoPhase = "Degraded"
+	// PhaseFailed means the last reconcile stopped on an error
+	PhaseFailed IAFDemoPhase = "Failed"
+)
+
+// Condition types, one per stage of the IAFDemo reconcile
+const (
+	ConditionCartridge             = "Cartridge"
+	ConditionAutomationBase        = "AutomationBase"
+	ConditionCartridgeRequirements = "CartridgeRequirements"
+	ConditionAIModels              = "AIModels"
+	ConditionModelVerified         = "ModelVerified"
+	ConditionRawKafkaTopic         = "RawKafkaTopic"
+	ConditionAnomalyKafkaTopic     = "AnomalyKafkaTopic"
+	ConditionDeadLetterKafkaTopic  = "DeadLetterKafkaTopic"
+	ConditionAlertsKafkaTopic      = "AlertsKafkaTopic"
+	ConditionKafkaUser             = "KafkaUser"
+	ConditionEventProcessor        = "EventProcessor"
+	ConditionElasticsearchIndices  = "ElasticsearchIndices"
+	ConditionEventProcessingTask   = "EventProcessingTask"
+	ConditionKnative               = "Knative"
+	ConditionProducerMicroservice  = "ProducerMicroservice"
+	ConditionServerMicroservice    = "ServerMicroservice"
+)
+
+// StageConditionTypes lists the stage conditions in the order the reconciler runs them
+var StageConditionTypes = []string{
+	ConditionCartridge,
+	ConditionAutomationBase,
+	ConditionCartridgeRequirements,
+	ConditionAIModels,
+	ConditionModelVerified,
+	ConditionRawKafkaTopic,
+	ConditionAnomalyKafkaTopic,
+	ConditionDeadLetterKafkaTopic,
+	ConditionAlertsKafkaTopic,
+	ConditionKafkaUser,
+	ConditionEventProcessor,
+	ConditionElasticsearchIndices,
+	ConditionEventProcessingTask,
+	ConditionProducerMicroservice,
+	ConditionServerMicroservice,
+	ConditionKnative,
+}
+
+// Condition reasons used by the IAFDemo reconciler
+const (
+	ReasonPending  = "Pending"
+	ReasonWaiting  = "Waiting"
+	ReasonReady    = "Ready"
+	ReasonFailed   = "Failed"
+	ReasonDisabled = "Disabled"
+	ReasonSkipped  = "Skipped"
+)
+
 // IAFDemoStatus defines the observed state of IAFDemo
 type IAFDemoStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Overall progress of the demo pipeline: Pending, Installing, Ready, Degraded or Failed
+	// +optional
+	Phase IAFDemoPhase `json:"phase,omitempty"`
+
+	// The generation of the IAFDemo that was last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// One condition for each stage of the reconcile, in the order they are run
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The AI models of the demo
+	// +optional
+	Models []ModelStatus `json:"models,omitempty"`
+
+	// The model predictor endpoints the EventProcessingTask was submitted with. It is empty while the job scores
+	// events with its built-in risk map.
+	// +optional
+	ModelPredictor *ModelPredictorStatus `json:"modelPredictor,omitempty"`
+}
+
+// ModelPredictorStatus reports the predictor endpoints the EventProcessingTask sends events to
+type ModelPredictorStatus struct {
+	// The predictor endpoint of the first model
+	Endpoint string `json:"endpoint"`
+
+	// The predictor endpoint of the candidate version of the first model, during a canary rollout
+	// +optional
+	CanaryEndpoint string `json:"canaryEndpoint,omitempty"`
+
+	// The percentage of the events sent to the candidate
+	// +optional
+	CanaryTrafficPercent int32 `json:"canaryTrafficPercent,omitempty"`
+}
+
+// ModelStatus reports where an AI model is served from
+type ModelStatus struct {
+	// The name of the model
+	Name string `json:"name"`
+
+	// The storage URI KFServing loads the model from, such as s3://iaf-ai/models/anomaly-classifier
+	// +optional
+	StorageURI string `json:"storageURI,omitempty"`
+
+	// The SHA-256 digest of the model files synced to the store, over their paths and contents
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// The versions of the model being served, with the predictor endpoint of each
+	// +optional
+	Versions []ModelVersionStatus `json:"versions,omitempty"`
+}
+
+// ModelVersionStatus reports the AIDeployment serving a version of a model and its predictor endpoint
+type ModelVersionStatus struct {
+	// The version of the model
+	Version string `json:"version"`
+
+	// The AIDeployment that serves the version
+	Deployment string `json:"deployment"`
+
+	// The predictor endpoint of the AIDeployment, once it is ready
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// The percentage of the events scored by this version
+	TrafficPercent int32 `json:"trafficPercent"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // IAFDemo is the Schema for the iafdemoes API
 type IAFDemo struct {
@@ -0,0 +1,305 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	// DefaultSecondsToPause is used when secondsToPause is not set
+	DefaultSecondsToPause = "0"
+	// DefaultSequenceRepititions is used when sequenceRepititions is not set
+	DefaultSequenceRepititions = "1"
+	// DefaultDeletionPolicy is used when deletionPolicy is not set
+	DefaultDeletionPolicy = DeletionPolicyRetain
+	// DefaultTopicPartitions is used when the partitions of a topic are not set
+	DefaultTopicPartitions = 1
+	// DefaultTopicReplicas is used when the replicas of a topic are not set
+	DefaultTopicReplicas = 1
+	// DefaultKafkaCluster is the Event Streams cluster used when the cluster of a topic is not set
+	DefaultKafkaCluster = "iaf-system"
+	// DefaultKnativeDelivery is used when the Knative delivery is not set
+	DefaultKnativeDelivery = KnativeDeliveryChannel
+	// DefaultKnativeChannelKind is used when the Knative channel kind is not set
+	DefaultKnativeChannelKind = KnativeChannelInMemory
+	// DefaultKnativeBrokerClass is used when the Knative Broker class is not set
+	DefaultKnativeBrokerClass = "MTChannelBasedBroker"
+	// DefaultKnativeListener is used when the Kafka listener of the KafkaSource is not set
+	DefaultKnativeListener = KafkaListenerTLS
+	// DefaultKnativeServerKind is used when it is not set how the server is deployed
+	DefaultKnativeServerKind = KnativeServerDeployment
+	// DefaultModelStoreType is used when the type of the model store is not set
+	DefaultModelStoreType = ModelStoreS3
+	// DefaultModelStoreSecret is the credentials Secret of the S3 model store, which the AI operator creates
+	DefaultModelStoreSecret = "minio-secret"
+	// DefaultModelStoreBucket is the bucket of the S3 model store when it is not set
+	DefaultModelStoreBucket = "iaf-ai"
+	// DefaultModelType is the framework of a model when it is not set
+	DefaultModelType = "tensorflow"
+	// DefaultModelVersion is the version of a model when it is not set
+	DefaultModelVersion = "1.0.0"
+	// DefaultModelRuntime is the AIRuntime, created by the operator, that serves a model when it is not set
+	DefaultModelRuntime = "kfservingruntime"
+	// DefaultCanaryTrafficPercent is the share of the events scored by a candidate model version when it is not set
+	DefaultCanaryTrafficPercent int32 = 10
+	// DefaultModelRollout is the stage of the rollout of a candidate model version when it is not set
+	DefaultModelRollout = ModelRolloutCanary
+	// CanaryNameSuffix is appended to the AIDeployment name of a model for its candidate version, and to the
+	// model name for the stored model that holds the candidate when canary.model is not set
+	CanaryNameSuffix = "-canary"
+
+	// MaxModelNameLength is the longest model name accepted, so that the AIModel and AIDeployment names
+	// derived from the IAFDemo and model names fit in a DNS label
+	MaxModelNameLength = 22
+
+	// MaxNameLength is the longest IAFDemo name accepted. The names of the child resources are
+	// derived from it, and the longest of them must still fit in a 63 character DNS label.
+	MaxNameLength = 40
+)
+
+// DefaultModel is deployed when no models are listed: the anomaly classifier bundled with the operator
+var DefaultModel = ModelSpec{
+	Name:        "anomaly-classifier",
+	Type:        DefaultModelType,
+	Version:     DefaultModelVersion,
+	Runtime:     DefaultModelRuntime,
+	Description: "Sample AI Model that categorises risk",
+}
+
+// log is for logging in this package.
+var iafdemolog = logf.Log.WithName("iafdemo-resource")
+
+// SetupWebhookWithManager registers the IAFDemo v1 webhooks with the manager's webhook server
+func (r *IAFDemo) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-democartridge-ibm-com-v1-iafdemo,mutating=true,failurePolicy=fail,groups=democartridge.ibm.com,resources=iafdemoes,verbs=create;update,versions=v1,name=miafdemo.kb.io
+
+var _ webhook.Defaulter = &IAFDemo{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *IAFDemo) Default() {
+	iafdemolog.Info("default", "name", r.Name, "namespace", r.Namespace)
+
+	// messagesPerGroup is left unset, which means all of the sample data is sent as one group
+	if r.Spec.SecondsToPause == "" {
+		r.Spec.SecondsToPause = DefaultSecondsToPause
+	}
+	if r.Spec.SequenceRepititions == "" {
+		r.Spec.SequenceRepititions = DefaultSequenceRepititions
+	}
+	if r.Spec.DeletionPolicy == "" {
+		r.Spec.DeletionPolicy = DefaultDeletionPolicy
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-democartridge-ibm-com-v1-iafdemo,mutating=false,failurePolicy=fail,groups=democartridge.ibm.com,resources=iafdemoes,versions=v1,name=viafdemo.kb.io
+
+var _ webhook.Validator = &IAFDemo{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *IAFDemo) ValidateCreate() error {
+	iafdemolog.Info("validate create", "name", r.Name, "namespace", r.Namespace)
+
+	allErrs := r.Spec.validate(field.NewPath("spec"))
+	if err := r.validateName(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, r.validateCanaryNames()...)
+	return r.toInvalidError(allErrs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *IAFDemo) ValidateUpdate(old runtime.Object) error {
+	iafdemolog.Info("validate update", "name", r.Name, "namespace", r.Namespace)
+
+	allErrs := r.Spec.validate(field.NewPath("spec"))
+	allErrs = append(allErrs, r.validateCanaryNames()...)
+	if oldDemo, ok := old.(*IAFDemo); ok {
+		allErrs = append(allErrs, r.Spec.Topics.validateUpdate(field.NewPath("spec", "topics"), &oldDemo.Spec.Topics)...)
+	}
+	return r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *IAFDemo) ValidateDelete() error {
+	return nil
+}
+
+func (r *IAFDemo) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("IAFDemo").GroupKind(), r.Name, allErrs)
+}
+
+// validateName rejects names too long to derive the child resource names from.
+func (r *IAFDemo) validateName() *field.Error {
+	if len(r.Name) > MaxNameLength {
+		return field.TooLong(field.NewPath("metadata", "name"), r.Name, MaxNameLength)
+	}
+	return nil
+}
+
+// validateCanaryNames rejects candidate model versions whose AIDeployment name, which is longer than the
+// name of the model's own AIDeployment, would not fit in a DNS label.
+func (r *IAFDemo) validateCanaryNames() field.ErrorList {
+	var allErrs field.ErrorList
+	for i, model := range r.Spec.Models {
+		name := r.Name + "-" + model.Name + CanaryNameSuffix
+		if model.Canary.Version != "" && len(name) > validation.DNS1123LabelMaxLength {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "models").Index(i).Child("canary"), model.Canary.Version,
+				fmt.Sprintf("the AIDeployment %s of the candidate must be no more than %d characters", name, validation.DNS1123LabelMaxLength)))
+		}
+	}
+	return allErrs
+}
+
+func (s *IAFDemoSpec) validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !bool(s.License.Accept) {
+		allErrs = append(allErrs, field.Invalid(path.Child("license", "accept"), s.License.Accept,
+			"the license terms at http://ibm.biz/IAF-license must be accepted"))
+	}
+	if err := validateCount(path.Child("messagesPerGroup"), s.MessagesPerGroup, 1, false); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateCount(path.Child("secondsToPause"), s.SecondsToPause, 0, false); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateCount(path.Child("sequenceRepititions"), s.SequenceRepititions, 1, true); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateModels(path.Child("models"), s.Models)...)
+	return allErrs
+}
+
+// validateModels requires unique model names that can be part of a DNS label
+func validateModels(path *field.Path, models []ModelSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[string]bool{}
+	for i, model := range models {
+		namePath := path.Index(i).Child("name")
+		for _, msg := range validation.IsDNS1123Label(model.Name) {
+			allErrs = append(allErrs, field.Invalid(namePath, model.Name, msg))
+		}
+		if len(model.Name) > MaxModelNameLength {
+			allErrs = append(allErrs, field.TooLong(namePath, model.Name, MaxModelNameLength))
+		}
+		if seen[model.Name] {
+			allErrs = append(allErrs, field.Duplicate(namePath, model.Name))
+		}
+		seen[model.Name] = true
+		allErrs = append(allErrs, model.Canary.validate(path.Index(i).Child("canary"), model.Name)...)
+	}
+	return allErrs
+}
+
+// validate requires the version of the candidate once any other canary setting is given, and a stored model
+// for the candidate other than that of the current version, since the AI operator loads a model by its name
+func (c *ModelCanary) validate(path *field.Path, model string) field.ErrorList {
+	var allErrs field.ErrorList
+	if c.Version == "" && (c.Model != "" || c.TrafficPercent != nil || c.Rollout != "") {
+		allErrs = append(allErrs, field.Required(path.Child("version"), "must be set to roll out a candidate version"))
+	}
+	if percent := c.TrafficPercentOrDefault(); percent < 0 || percent > 100 {
+		allErrs = append(allErrs, field.Invalid(path.Child("trafficPercent"), percent, "must be between 0 and 100"))
+	}
+	if c.Model != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(c.Model) {
+			allErrs = append(allErrs, field.Invalid(path.Child("model"), c.Model, msg))
+		}
+		if c.Model == model {
+			allErrs = append(allErrs, field.Invalid(path.Child("model"), c.Model, "must differ from the name of the model"))
+		}
+	}
+	return allErrs
+}
+
+// validateUpdate rejects fewer partitions than a topic already has, because Kafka cannot remove partitions
+func (t *IAFDemoTopics) validateUpdate(path *field.Path, old *IAFDemoTopics) field.ErrorList {
+	var allErrs field.ErrorList
+	if err := validatePartitions(path.Child("raw", "partitions"), t.Raw, old.Raw); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePartitions(path.Child("anomaly", "partitions"), t.Anomaly, old.Anomaly); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePartitions(path.Child("deadLetter", "partitions"), t.DeadLetter, old.DeadLetter); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePartitions(path.Child("alerts", "partitions"), t.Alerts, old.Alerts); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func validatePartitions(path *field.Path, settings, old KafkaTopicSettings) *field.Error {
+	if settings.PartitionsOrDefault() < old.PartitionsOrDefault() {
+		return field.Invalid(path, settings.PartitionsOrDefault(),
+			fmt.Sprintf("must be at least %d, the partitions of a Kafka topic cannot be reduced", old.PartitionsOrDefault()))
+	}
+	return nil
+}
+
+// validateCount checks that an optional string count is a whole number no smaller than min that fits the
+// int32 of the v2 API. When allowForever is set, -1 is also accepted.
+func validateCount(path *field.Path, value string, min int64, allowForever bool) *field.Error {
+	if value == "" {
+		return nil
+	}
+	count, err := strconv.ParseInt(value, 10, 32)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return field.Invalid(path, value, fmt.Sprintf("must be at most %d", math.MaxInt32))
+	} else if err != nil {
+		return field.Invalid(path, value, "must be a whole number")
+	}
+	if allowForever && count == -1 {
+		return nil
+	}
+	if count < min {
+		if allowForever {
+			return field.Invalid(path, value, fmt.Sprintf("must be at least %d, or -1 to repeat forever", min))
+		}
+		return field.Invalid(path, value, fmt.Sprintf("must be at least %d", min))
+	}
+	return nil
+}
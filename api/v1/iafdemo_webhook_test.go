@@ -0,0 +1,96 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/commoncrd"
+)
+
+func TestDefault(t *testing.T) {
+	demo := &IAFDemo{Spec: IAFDemoSpec{License: commoncrd.License{Accept: true}}}
+	demo.Default()
+	if demo.Spec.MessagesPerGroup != "" || demo.Spec.SecondsToPause != DefaultSecondsToPause || demo.Spec.SequenceRepititions != DefaultSequenceRepititions || demo.Spec.DeletionPolicy != DeletionPolicyRetain {
+		t.Errorf("unexpected defaulted spec %+v", demo.Spec)
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		demoName string
+		spec     IAFDemoSpec
+		wantErr  bool
+	}{
+		{"defaults", "iafdemo-sample", IAFDemoSpec{License: commoncrd.License{Accept: true}}, false},
+		{"forever", "iafdemo-sample", IAFDemoSpec{SequenceRepititions: "-1", License: commoncrd.License{Accept: true}}, false},
+		{"license not accepted", "iafdemo-sample", IAFDemoSpec{}, true},
+		{"typo in messagesPerGroup", "iafdemo-sample", IAFDemoSpec{MessagesPerGroup: "1O", License: commoncrd.License{Accept: true}}, true},
+		{"zero messagesPerGroup", "iafdemo-sample", IAFDemoSpec{MessagesPerGroup: "0", License: commoncrd.License{Accept: true}}, true},
+		{"messagesPerGroup beyond int32", "iafdemo-sample", IAFDemoSpec{MessagesPerGroup: "3000000000", License: commoncrd.License{Accept: true}}, true},
+		{"negative secondsToPause", "iafdemo-sample", IAFDemoSpec{SecondsToPause: "-5", License: commoncrd.License{Accept: true}}, true},
+		{"name too long", strings.Repeat("a", MaxNameLength+1), IAFDemoSpec{License: commoncrd.License{Accept: true}}, true},
+		{"zero sequenceRepititions", "iafdemo-sample", IAFDemoSpec{SequenceRepititions: "0", License: commoncrd.License{Accept: true}}, true},
+		{"models", "iafdemo-sample", IAFDemoSpec{Models: []ModelSpec{DefaultModel, {Name: "fraud-scorer"}}, License: commoncrd.License{Accept: true}}, false},
+		{"duplicate model", "iafdemo-sample", IAFDemoSpec{Models: []ModelSpec{DefaultModel, DefaultModel}, License: commoncrd.License{Accept: true}}, true},
+		{"model name not a DNS label", "iafdemo-sample", IAFDemoSpec{Models: []ModelSpec{{Name: "Fraud_Scorer"}}, License: commoncrd.License{Accept: true}}, true},
+		{"canary", "iafdemo-sample", IAFDemoSpec{Models: []ModelSpec{{Name: "fraud-scorer", Canary: ModelCanary{Version: "2.0.0", Rollout: ModelRolloutPromote}}}, License: commoncrd.License{Accept: true}}, false},
+		{"canary without version", "iafdemo-sample", IAFDemoSpec{Models: []ModelSpec{{Name: "fraud-scorer", Canary: ModelCanary{Rollout: ModelRolloutPromote}}}, License: commoncrd.License{Accept: true}}, true},
+		{"canary served from the current model", "iafdemo-sample", IAFDemoSpec{Models: []ModelSpec{{Name: "fraud-scorer", Canary: ModelCanary{Version: "2.0.0", Model: "fraud-scorer"}}}, License: commoncrd.License{Accept: true}}, true},
+		{"canary name too long", strings.Repeat("a", MaxNameLength), IAFDemoSpec{Models: []ModelSpec{{Name: "anomaly-classifier", Canary: ModelCanary{Version: "2.0.0"}}}, License: commoncrd.License{Accept: true}}, true},
+	}
+	for _, tt := range tests {
+		demo := &IAFDemo{Spec: tt.spec}
+		demo.Name = tt.demoName
+		if err := demo.ValidateCreate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateCreate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateUpdatePartitions(t *testing.T) {
+	two, three := int32(2), int32(3)
+	tests := []struct {
+		name    string
+		old     KafkaTopicSettings
+		new     KafkaTopicSettings
+		wantErr bool
+	}{
+		{"unchanged", KafkaTopicSettings{}, KafkaTopicSettings{}, false},
+		{"increased from default", KafkaTopicSettings{}, KafkaTopicSettings{Partitions: &two}, false},
+		{"reduced", KafkaTopicSettings{Partitions: &three}, KafkaTopicSettings{Partitions: &two}, true},
+		{"reset to default", KafkaTopicSettings{Partitions: &two}, KafkaTopicSettings{}, true},
+	}
+	for _, tt := range tests {
+		old := &IAFDemo{Spec: IAFDemoSpec{Topics: IAFDemoTopics{Raw: tt.old}, License: commoncrd.License{Accept: true}}}
+		demo := &IAFDemo{Spec: IAFDemoSpec{Topics: IAFDemoTopics{Raw: tt.new}, License: commoncrd.License{Accept: true}}}
+		if err := demo.ValidateUpdate(old); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateUpdate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
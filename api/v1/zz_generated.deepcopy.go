@@ -30,7 +30,10 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -38,8 +41,8 @@ func (in *IAFDemo) DeepCopyInto(out *IAFDemo) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAFDemo.
@@ -60,6 +63,73 @@ func (in *IAFDemo) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAFDemoComponents) DeepCopyInto(out *IAFDemoComponents) {
+	*out = *in
+	if in.AI != nil {
+		in, out := &in.AI, &out.AI
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Knative != nil {
+		in, out := &in.Knative, &out.Knative
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Producer != nil {
+		in, out := &in.Producer, &out.Producer
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAFDemoComponents.
+func (in *IAFDemoComponents) DeepCopy() *IAFDemoComponents {
+	if in == nil {
+		return nil
+	}
+	out := new(IAFDemoComponents)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAFDemoKnative) DeepCopyInto(out *IAFDemoKnative) {
+	*out = *in
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAFDemoKnative.
+func (in *IAFDemoKnative) DeepCopy() *IAFDemoKnative {
+	if in == nil {
+		return nil
+	}
+	out := new(IAFDemoKnative)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IAFDemoList) DeepCopyInto(out *IAFDemoList) {
 	*out = *in
@@ -95,6 +165,17 @@ func (in *IAFDemoList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IAFDemoSpec) DeepCopyInto(out *IAFDemoSpec) {
 	*out = *in
+	in.Components.DeepCopyInto(&out.Components)
+	in.Topics.DeepCopyInto(&out.Topics)
+	in.Knative.DeepCopyInto(&out.Knative)
+	in.ModelStore.DeepCopyInto(&out.ModelStore)
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]ModelSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.License = in.License
 }
 
@@ -111,6 +192,25 @@ func (in *IAFDemoSpec) DeepCopy() *IAFDemoSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IAFDemoStatus) DeepCopyInto(out *IAFDemoStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]ModelStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ModelPredictor != nil {
+		in, out := &in.ModelPredictor, &out.ModelPredictor
+		*out = new(ModelPredictorStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAFDemoStatus.
@@ -122,3 +222,181 @@ func (in *IAFDemoStatus) DeepCopy() *IAFDemoStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAFDemoTopics) DeepCopyInto(out *IAFDemoTopics) {
+	*out = *in
+	in.Raw.DeepCopyInto(&out.Raw)
+	in.Anomaly.DeepCopyInto(&out.Anomaly)
+	in.DeadLetter.DeepCopyInto(&out.DeadLetter)
+	in.Alerts.DeepCopyInto(&out.Alerts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAFDemoTopics.
+func (in *IAFDemoTopics) DeepCopy() *IAFDemoTopics {
+	if in == nil {
+		return nil
+	}
+	out := new(IAFDemoTopics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTopicSettings) DeepCopyInto(out *KafkaTopicSettings) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]intstr.IntOrString, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicSettings.
+func (in *KafkaTopicSettings) DeepCopy() *KafkaTopicSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTopicSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelCanary) DeepCopyInto(out *ModelCanary) {
+	*out = *in
+	if in.TrafficPercent != nil {
+		in, out := &in.TrafficPercent, &out.TrafficPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelCanary.
+func (in *ModelCanary) DeepCopy() *ModelCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelPredictorStatus) DeepCopyInto(out *ModelPredictorStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelPredictorStatus.
+func (in *ModelPredictorStatus) DeepCopy() *ModelPredictorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelPredictorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
+	*out = *in
+	in.Canary.DeepCopyInto(&out.Canary)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSpec.
+func (in *ModelSpec) DeepCopy() *ModelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelStatus) DeepCopyInto(out *ModelStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]ModelVersionStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelStatus.
+func (in *ModelStatus) DeepCopy() *ModelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelStore) DeepCopyInto(out *ModelStore) {
+	*out = *in
+	in.S3.DeepCopyInto(&out.S3)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelStore.
+func (in *ModelStore) DeepCopy() *ModelStore {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelVersionStatus) DeepCopyInto(out *ModelVersionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelVersionStatus.
+func (in *ModelVersionStatus) DeepCopy() *ModelVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ModelStore) DeepCopyInto(out *S3ModelStore) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ModelStore.
+func (in *S3ModelStore) DeepCopy() *S3ModelStore {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ModelStore)
+	in.DeepCopyInto(out)
+	return out
+}
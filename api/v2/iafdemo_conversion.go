@@ -0,0 +1,120 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this IAFDemo to the Hub version (v1).
+func (src *IAFDemo) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*democartridgev1.IAFDemo)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.MessagesPerGroup = formatCount(src.Spec.MessagesPerGroup)
+	dst.Spec.SecondsToPause = formatCount(src.Spec.SecondsToPause)
+	dst.Spec.SequenceRepititions = formatCount(src.Spec.SequenceRepetitions)
+	src.Spec.Components.DeepCopyInto(&dst.Spec.Components)
+	src.Spec.Topics.DeepCopyInto(&dst.Spec.Topics)
+	src.Spec.Knative.DeepCopyInto(&dst.Spec.Knative)
+	src.Spec.ModelStore.DeepCopyInto(&dst.Spec.ModelStore)
+	dst.Spec.Models = copyModels(src.Spec.Models)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+	dst.Spec.License = src.Spec.License
+	src.Status.DeepCopyInto(&dst.Status)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+func (dst *IAFDemo) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*democartridgev1.IAFDemo)
+	var err error
+
+	dst.ObjectMeta = src.ObjectMeta
+	if dst.Spec.MessagesPerGroup, err = parseCount("messagesPerGroup", src.Spec.MessagesPerGroup); err != nil {
+		return err
+	}
+	if dst.Spec.SecondsToPause, err = parseCount("secondsToPause", src.Spec.SecondsToPause); err != nil {
+		return err
+	}
+	if dst.Spec.SequenceRepetitions, err = parseCount("sequenceRepititions", src.Spec.SequenceRepititions); err != nil {
+		return err
+	}
+	src.Spec.Components.DeepCopyInto(&dst.Spec.Components)
+	src.Spec.Topics.DeepCopyInto(&dst.Spec.Topics)
+	src.Spec.Knative.DeepCopyInto(&dst.Spec.Knative)
+	src.Spec.ModelStore.DeepCopyInto(&dst.Spec.ModelStore)
+	dst.Spec.Models = copyModels(src.Spec.Models)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+	dst.Spec.License = src.Spec.License
+	src.Status.DeepCopyInto(&dst.Status)
+	return nil
+}
+
+// copyModels deep copies the models, which the versions share
+func copyModels(models []democartridgev1.ModelSpec) []democartridgev1.ModelSpec {
+	if models == nil {
+		return nil
+	}
+	copied := make([]democartridgev1.ModelSpec, len(models))
+	for i := range models {
+		models[i].DeepCopyInto(&copied[i])
+	}
+	return copied
+}
+
+// formatCount renders an optional v2 count as the string form used by v1, where unset is the empty string.
+func formatCount(count *int32) string {
+	if count == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(*count), 10)
+}
+
+// parseCount parses a v1 string count. The empty string is left unset so the producer applies its default.
+// The v1 pattern allows any number of digits, so a count stored before the webhook checked its range is
+// clamped to the int32 range rather than failing every v2 read of the object.
+func parseCount(field, value string) (*int32, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	count, err := strconv.ParseInt(value, 10, 32)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		// ParseInt returns the closest int32 along with the range error
+		err = nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to convert %s %q to an integer: %w", field, value, err)
+	}
+	result := int32(count)
+	return &result, nil
+}
@@ -0,0 +1,103 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"testing"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/commoncrd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	disabled := false
+	v2 := &IAFDemo{
+		ObjectMeta: metav1.ObjectMeta{Name: "iafdemo-sample", Namespace: "demo"},
+		Spec: IAFDemoSpec{
+			MessagesPerGroup:    int32Ptr(10),
+			SecondsToPause:      int32Ptr(0),
+			SequenceRepetitions: int32Ptr(-1),
+			Components:          democartridgev1.IAFDemoComponents{AI: &disabled},
+			DeletionPolicy:      democartridgev1.DeletionPolicyRetain,
+			License:             commoncrd.License{Accept: true},
+		},
+	}
+
+	hub := &democartridgev1.IAFDemo{}
+	if err := v2.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo failed: %v", err)
+	}
+	if hub.Spec.MessagesPerGroup != "10" || hub.Spec.SecondsToPause != "0" || hub.Spec.SequenceRepititions != "-1" {
+		t.Errorf("unexpected v1 spec %+v", hub.Spec)
+	}
+
+	back := &IAFDemo{}
+	if err := back.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	if *back.Spec.MessagesPerGroup != 10 || *back.Spec.SecondsToPause != 0 || *back.Spec.SequenceRepetitions != -1 {
+		t.Errorf("unexpected v2 spec %+v", back.Spec)
+	}
+	if back.Name != "iafdemo-sample" || !bool(back.Spec.License.Accept) || back.Spec.DeletionPolicy != democartridgev1.DeletionPolicyRetain {
+		t.Errorf("metadata, license or deletion policy lost in conversion: %+v", back)
+	}
+	if back.Spec.Components.AI == nil || *back.Spec.Components.AI || back.Spec.Components.AI == v2.Spec.Components.AI {
+		t.Errorf("components not deep copied in conversion: %+v", back.Spec.Components)
+	}
+}
+
+func TestConvertFromUnsetAndInvalid(t *testing.T) {
+	dst := &IAFDemo{}
+	if err := dst.ConvertFrom(&democartridgev1.IAFDemo{}); err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	if dst.Spec.MessagesPerGroup != nil || dst.Spec.SecondsToPause != nil || dst.Spec.SequenceRepetitions != nil {
+		t.Errorf("expected unset v1 fields to stay unset, got %+v", dst.Spec)
+	}
+
+	hub := &democartridgev1.IAFDemo{Spec: democartridgev1.IAFDemoSpec{MessagesPerGroup: "ten"}}
+	if err := dst.ConvertFrom(hub); err == nil {
+		t.Errorf("expected an error converting messagesPerGroup %q", hub.Spec.MessagesPerGroup)
+	}
+}
+
+func TestConvertFromOutOfRange(t *testing.T) {
+	// The v1 pattern allows any number of digits, so such a value may already be stored
+	hub := &democartridgev1.IAFDemo{Spec: democartridgev1.IAFDemoSpec{MessagesPerGroup: "3000000000", SecondsToPause: "99999999999999999999"}}
+	dst := &IAFDemo{}
+	if err := dst.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	if *dst.Spec.MessagesPerGroup != math.MaxInt32 || *dst.Spec.SecondsToPause != math.MaxInt32 {
+		t.Errorf("expected out of range counts to be clamped to %d, got %d and %d", math.MaxInt32, *dst.Spec.MessagesPerGroup, *dst.Spec.SecondsToPause)
+	}
+}
@@ -0,0 +1,107 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/commoncrd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IAFDemoSpec defines the desired state of IAFDemo
+type IAFDemoSpec struct {
+	// Number of messages to put on Kafka topic all at once. When unset all of the sample data is sent as one group.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MessagesPerGroup *int32 `json:"messagesPerGroup,omitempty"`
+
+	// Number of seconds to pause between groups of Kafka messages. Default is 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SecondsToPause *int32 `json:"secondsToPause,omitempty"`
+
+	// Number of times to submit the 1725 rows of sample data. Default is 1; -1 means keep submitting forever.
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	SequenceRepetitions *int32 `json:"sequenceRepetitions,omitempty"`
+
+	// Switches for the optional parts of the demo
+	// +optional
+	Components democartridgev1.IAFDemoComponents `json:"components,omitempty"`
+
+	// Settings of the Kafka topics of the demo
+	// +optional
+	Topics democartridgev1.IAFDemoTopics `json:"topics,omitempty"`
+
+	// How the anomalies are delivered to the server when spec.components.knative is on
+	// +optional
+	Knative democartridgev1.IAFDemoKnative `json:"knative,omitempty"`
+
+	// Where the AI models are stored for KFServing to load them from
+	// +optional
+	ModelStore democartridgev1.ModelStore `json:"modelStore,omitempty"`
+
+	// The AI models to deploy, each with an AIModel and an AIDeployment. The EventProcessingTask scores events
+	// with the first one. Default is the anomaly-classifier model bundled with the operator.
+	// +optional
+	Models []democartridgev1.ModelSpec `json:"models,omitempty"`
+
+	// What to do with the Elasticsearch indices and the AI models uploaded to MinIO when the IAFDemo is deleted.
+	// Default is Retain.
+	// +optional
+	DeletionPolicy democartridgev1.DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// By installing this component you accept the license terms http://ibm.biz/IAF-license
+	License commoncrd.License `json:"license"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// IAFDemo is the Schema for the iafdemoes API
+type IAFDemo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IAFDemoSpec `json:"spec,omitempty"`
+	// The status is unchanged from v1
+	Status democartridgev1.IAFDemoStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IAFDemoList contains a list of IAFDemo
+type IAFDemoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IAFDemo `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IAFDemo{}, &IAFDemoList{})
+}
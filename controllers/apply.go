@@ -0,0 +1,193 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// createOrPatch brings a child of the IAFDemo to its desired state. obj only needs its name and namespace set;
+// it is read from the cluster, mutate sets the fields the operator manages, and the result is either created
+// or, when mutate changed anything, sent as a merge patch. Fields set by the server or other controllers are
+// left alone, so mutate should only touch the fields it owns.
+func (r *IAFDemoReconciler) createOrPatch(recctx *reconcileContext, obj runtime.Object, mutate func() error) (controllerutil.OperationResult, error) {
+	return r.applyChild(recctx, obj, true, mutate)
+}
+
+// createOrPatchShared is createOrPatch for a resource the IAFDemos in a namespace share. It carries no owner
+// reference to an IAFDemo, so that garbage collection leaves it to the others when one is deleted.
+func (r *IAFDemoReconciler) createOrPatchShared(recctx *reconcileContext, obj runtime.Object, mutate func() error) (controllerutil.OperationResult, error) {
+	return r.applyChild(recctx, obj, false, mutate)
+}
+
+func (r *IAFDemoReconciler) applyChild(recctx *reconcileContext, obj runtime.Object, owned bool, mutate func() error) (controllerutil.OperationResult, error) {
+	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
+
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	kind := gvk.Kind
+
+	err = r.Get(*recctx.ctx, key, obj)
+	if err != nil && !errors.IsNotFound(err) {
+		return controllerutil.OperationResultNone, fmt.Errorf("Failed to get %s %s in Namespace %s: %w", kind, key.Name, key.Namespace, err)
+	}
+
+	if errors.IsNotFound(err) {
+		log.Info(kind + " " + key.Name + " not found. Creating...")
+		if err = mutate(); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err = r.setOwner(recctx, objMeta, owned); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err = r.Create(*recctx.ctx, obj); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("Failed to create new %s %s in Namespace %s: %w", kind, key.Name, key.Namespace, err)
+		}
+		r.recordCreated(recctx, kind, key.Name)
+		return controllerutil.OperationResultCreated, nil
+	}
+
+	existing := obj.DeepCopyObject()
+	if err = mutate(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if err = r.setOwner(recctx, objMeta, owned); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if equality.Semantic.DeepEqual(existing, obj) {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	log.Info(kind + " " + key.Name + " differs from the desired state. Patching...")
+	if err = r.Patch(*recctx.ctx, obj, client.MergeFrom(existing)); err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("Failed to patch %s %s in Namespace %s: %w", kind, key.Name, key.Namespace, err)
+	}
+	r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonPatched, "Patched %s %s to match the desired state", kind, key.Name)
+	return controllerutil.OperationResultUpdated, nil
+}
+
+// setOwner makes the IAFDemo the controller of an owned child, and removes the owner references to IAFDemos
+// from a shared one
+func (r *IAFDemoReconciler) setOwner(recctx *reconcileContext, objMeta metav1.Object, owned bool) error {
+	if !owned {
+		objMeta.SetOwnerReferences(withoutIAFDemoOwners(objMeta.GetOwnerReferences()))
+		return nil
+	}
+	if err := ctrl.SetControllerReference(recctx.iafdemo, objMeta, r.Scheme); err != nil {
+		return fmt.Errorf("Failed to set controller reference: %s", err)
+	}
+	return nil
+}
+
+// deleteIfExists deletes a child of the IAFDemo, treating a child that is already gone, or whose kind
+// is not installed in the cluster, as deleted
+func (r *IAFDemoReconciler) deleteIfExists(recctx *reconcileContext, obj runtime.Object) error {
+	key, _ := client.ObjectKeyFromObject(obj)
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, gvkErr := apiutil.GVKForObject(obj, r.Scheme); gvkErr == nil {
+		kind = gvk.Kind
+	}
+	err := r.Delete(*recctx.ctx, obj)
+	if err == nil {
+		r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonDeleted, "Deleted %s %s", kind, key.Name)
+		return nil
+	}
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	return fmt.Errorf("Failed to delete %s %s in Namespace %s: %w", kind, key.Name, key.Namespace, err)
+}
+
+// removeIAFDemoOwners removes the owner references to IAFDemos from a resource the IAFDemos in a namespace share,
+// which earlier operator versions set to the IAFDemo that created it. Garbage collection would otherwise delete
+// the resource with that IAFDemo while the others still use it.
+func (r *IAFDemoReconciler) removeIAFDemoOwners(recctx *reconcileContext, obj runtime.Object) error {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	owners := withoutIAFDemoOwners(objMeta.GetOwnerReferences())
+	if len(owners) == len(objMeta.GetOwnerReferences()) {
+		return nil
+	}
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, gvkErr := apiutil.GVKForObject(obj, r.Scheme); gvkErr == nil {
+		kind = gvk.Kind
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject())
+	objMeta.SetOwnerReferences(owners)
+	if err := r.Patch(*recctx.ctx, obj, patch); err != nil {
+		return fmt.Errorf("Failed to remove the owner reference from %s %s in Namespace %s: %w", kind, objMeta.GetName(), objMeta.GetNamespace(), err)
+	}
+	r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonPatched, "Removed the owner reference from %s %s", kind, objMeta.GetName())
+	return nil
+}
+
+// withoutIAFDemoOwners returns the owner references that do not point to an IAFDemo
+func withoutIAFDemoOwners(references []metav1.OwnerReference) []metav1.OwnerReference {
+	var owners []metav1.OwnerReference
+	for _, owner := range references {
+		if owner.Kind != "IAFDemo" || !strings.HasPrefix(owner.APIVersion, democartridgev1.GroupVersion.Group+"/") {
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// mergeStringMap returns existing labels or annotations with the desired ones set, keeping any added by others
+func mergeStringMap(existing, desired map[string]string) map[string]string {
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for key, value := range desired {
+		existing[key] = value
+	}
+	return existing
+}
@@ -0,0 +1,172 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	knkafkasource "knative.dev/eventing-contrib/kafka/source/pkg/apis/sources/v1alpha1"
+
+	kafkatopics "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1kafka"
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// componentEnabled reads an optional switch from spec.components
+func componentEnabled(toggle *bool, defaultValue bool) bool {
+	if toggle == nil {
+		return defaultValue
+	}
+	return *toggle
+}
+
+func (r *IAFDemoReconciler) aiEnabled(recctx *reconcileContext) (bool, string) {
+	return componentEnabled(recctx.iafdemo.Spec.Components.AI, true), "AI scoring is disabled by spec.components.ai"
+}
+
+func (r *IAFDemoReconciler) elasticsearchEnabled(recctx *reconcileContext) (bool, string) {
+	return componentEnabled(recctx.iafdemo.Spec.Components.Elasticsearch, true), "Elasticsearch indexing is disabled by spec.components.elasticsearch"
+}
+
+func (r *IAFDemoReconciler) producerEnabled(recctx *reconcileContext) (bool, string) {
+	return componentEnabled(recctx.iafdemo.Spec.Components.Producer, true), "The producer is disabled by spec.components.producer"
+}
+
+func (r *IAFDemoReconciler) serverEnabled(recctx *reconcileContext) (bool, string) {
+	return componentEnabled(recctx.iafdemo.Spec.Components.Server, true), "The server is disabled by spec.components.server"
+}
+
+func (r *IAFDemoReconciler) alertsEnabled(recctx *reconcileContext) (bool, string) {
+	return componentEnabled(recctx.iafdemo.Spec.Components.Alerts, false), "The alerts topic is disabled by spec.components.alerts"
+}
+
+// alertsTopic returns the name of the alerts topic, or "" when the alerts topic is disabled
+func (r *IAFDemoReconciler) alertsTopic(recctx *reconcileContext) string {
+	if enabled, _ := r.alertsEnabled(recctx); !enabled {
+		return ""
+	}
+	return recctx.names.alertsTopic
+}
+
+func (r *IAFDemoReconciler) knativeEnabled(recctx *reconcileContext) (bool, string) {
+	if enabled, _ := r.serverEnabled(recctx); !enabled {
+		return false, "Knative delivers to the server, which is disabled by spec.components.server"
+	}
+	knativeDefault := strings.EqualFold(r.Cfg.UseKnative, "true")
+	return componentEnabled(recctx.iafdemo.Spec.Components.Knative, knativeDefault), "Knative is disabled by spec.components.knative or USE_KNATIVE"
+}
+
+// serverKnativeService reports whether the server runs as a Knative Service rather than as a Deployment,
+// which spec.knative.serverKind selects while Knative is enabled
+func (r *IAFDemoReconciler) serverKnativeService(recctx *reconcileContext) bool {
+	if enabled, _ := r.knativeEnabled(recctx); !enabled {
+		return false
+	}
+	return recctx.iafdemo.Spec.Knative.ServerKindOrDefault() == democartridgev1.KnativeServerService
+}
+
+// deleteAIResources removes the AIDeployments and AIModels of the IAFDemo. The AIRuntime and the model store
+// are shared with the other IAFDemos in the namespace, so they are left for the finalizer.
+func (r *IAFDemoReconciler) deleteAIResources(recctx *reconcileContext) error {
+	recctx.iafdemo.Status.Models = nil
+	return r.deleteRemovedAIModels(recctx, nil)
+}
+
+// cleanupElasticsearchIndices removes the indices of the IAFDemo unless its deletion policy retains them
+func (r *IAFDemoReconciler) cleanupElasticsearchIndices(recctx *reconcileContext) error {
+	if recctx.iafdemo.Spec.DeletionPolicyOrDefault() == democartridgev1.DeletionPolicyRetain {
+		return nil
+	}
+	return r.deleteElasticsearchIndices(recctx)
+}
+
+func (r *IAFDemoReconciler) deleteKnative(recctx *reconcileContext) error {
+	deployedName := recctx.names.microservice(serverFunction)
+	if err := r.deleteKnativeBroker(recctx, deployedName); err != nil {
+		return err
+	}
+	if err := r.deleteKnativeChannel(recctx, deployedName); err != nil {
+		return err
+	}
+	namespace := recctx.iafdemo.Namespace
+	return r.deleteAll(recctx,
+		&knkafkasource.KafkaSource{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: recctx.names.kafkaSourceSecret, Namespace: namespace}})
+}
+
+func (r *IAFDemoReconciler) deleteKafkaTopic(recctx *reconcileContext, topicName string) error {
+	return r.deleteIfExists(recctx, &kafkatopics.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: topicName, Namespace: recctx.iafdemo.Namespace}})
+}
+
+func (r *IAFDemoReconciler) deleteMicroservice(recctx *reconcileContext, shortName string) error {
+	if err := r.deleteMicroserviceDeployment(recctx, shortName); err != nil {
+		return err
+	}
+	if shortName != serverFunction {
+		return nil
+	}
+	return r.deleteIfExists(recctx, newKnativeServiceObject(recctx.names.microservice(shortName), recctx.iafdemo.Namespace))
+}
+
+// deleteMicroserviceDeployment removes the Deployment of a microservice with the Service and Route in front of it.
+// Knative Serving creates a Service with the same name for a Knative Service, so only a Service controlled by
+// the IAFDemo is deleted.
+func (r *IAFDemoReconciler) deleteMicroserviceDeployment(recctx *reconcileContext, shortName string) error {
+	deployedName := recctx.names.microservice(shortName)
+	namespace := recctx.iafdemo.Namespace
+	err := r.deleteAll(recctx,
+		&routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}})
+	if err != nil {
+		return err
+	}
+	service := &corev1.Service{}
+	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, service)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(service, recctx.iafdemo) {
+		return nil
+	}
+	return r.deleteIfExists(recctx, service)
+}
+
+func (r *IAFDemoReconciler) deleteAll(recctx *reconcileContext, objs ...runtime.Object) error {
+	for _, obj := range objs {
+		if err := r.deleteIfExists(recctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
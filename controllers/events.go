@@ -0,0 +1,74 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// Reasons of the events recorded on an IAFDemo
+const (
+	eventReasonCreated       = "Created"
+	eventReasonPatched       = "Patched"
+	eventReasonDeleted       = "Deleted"
+	eventReasonCompleted     = "Completed"
+	eventReasonCleanedUp     = "CleanedUp"
+	eventReasonCleanupFailed = "CleanupFailed"
+	eventReasonModelsSynced  = "ModelsSynced"
+	eventReasonRecreated     = "Recreated"
+	eventReasonJobCancelled  = "JobCancelled"
+)
+
+// recordEvent records an event on the IAFDemo being reconciled
+func (r *IAFDemoReconciler) recordEvent(recctx *reconcileContext, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(recctx.iafdemo, eventType, reason, messageFmt, args...)
+}
+
+// recordCreated records that a child of the IAFDemo was created
+func (r *IAFDemoReconciler) recordCreated(recctx *reconcileContext, kind, name string) {
+	r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonCreated, "Created %s %s", kind, name)
+}
+
+// recordStageEvent records the outcome of a stage when it differs from the previous one, so that
+// repeated reconciles of a stage that is still waiting do not flood the events. A failure is a Warning.
+func (r *IAFDemoReconciler) recordStageEvent(recctx *reconcileContext, stageName string, previous *metav1.Condition) {
+	current := meta.FindStatusCondition(recctx.iafdemo.Status.Conditions, stageName)
+	if current == nil || (previous != nil && previous.Reason == current.Reason && previous.Message == current.Message) {
+		return
+	}
+	eventType := corev1.EventTypeNormal
+	if current.Reason == democartridgev1.ReasonFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	r.recordEvent(recctx, eventType, stageName+current.Reason, "%s", current.Message)
+}
@@ -0,0 +1,102 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// iafdemoFinalizer holds an IAFDemo until the state it created outside of Kubernetes has been cleaned up.
+// The children of the IAFDemo are removed by garbage collection through the owner references, apart from
+// the AutomationBase and AIRuntime that every IAFDemo in the namespace shares, which are deleted with the last of them,
+// and the AIModels, which are deleted with the last IAFDemo that lists them.
+const iafdemoFinalizer = "democartridge.ibm.com/finalizer"
+
+// addFinalizer makes sure the IAFDemo carries the finalizer before anything is created for it
+func (r *IAFDemoReconciler) addFinalizer(recctx *reconcileContext) error {
+	if controllerutil.ContainsFinalizer(recctx.iafdemo, iafdemoFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(recctx.iafdemo, iafdemoFinalizer)
+	return r.Update(*recctx.ctx, recctx.iafdemo)
+}
+
+// reconcileDelete applies the deletion policy of an IAFDemo that is being deleted, then releases the finalizer
+func (r *IAFDemoReconciler) reconcileDelete(recctx *reconcileContext) (ctrl.Result, error) {
+	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
+
+	if !controllerutil.ContainsFinalizer(recctx.iafdemo, iafdemoFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if recctx.iafdemo.Spec.DeletionPolicyOrDefault() == democartridgev1.DeletionPolicyRetain {
+		log.Info("Retaining Elasticsearch indices and AI models")
+	} else {
+		if err := r.deleteElasticsearchIndices(recctx); err != nil {
+			log.Error(err, "Failed to delete Elasticsearch indices")
+			r.recordEvent(recctx, corev1.EventTypeWarning, eventReasonCleanupFailed, "Failed to delete Elasticsearch indices: %v", err)
+			return ctrl.Result{}, err
+		}
+		if err := r.deleteAIModelStore(recctx); err != nil {
+			log.Error(err, "Failed to delete AI models")
+			r.recordEvent(recctx, corev1.EventTypeWarning, eventReasonCleanupFailed, "Failed to delete AI models: %v", err)
+			return ctrl.Result{}, err
+		}
+		r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonCleanedUp, "Deleted Elasticsearch indices and AI models")
+	}
+
+	if err := r.deleteSharedResources(recctx); err != nil {
+		log.Error(err, "Failed to delete the shared AI resources and AutomationBase")
+		r.recordEvent(recctx, corev1.EventTypeWarning, eventReasonCleanupFailed, "Failed to delete the shared AI resources and AutomationBase: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	forgetStages(recctx, r.stages())
+	controllerutil.RemoveFinalizer(recctx.iafdemo, iafdemoFinalizer)
+	return ctrl.Result{}, r.Update(*recctx.ctx, recctx.iafdemo)
+}
+
+// otherIAFDemo returns the name of another IAFDemo in the namespace that is not being deleted, or "" if there is
+// none, to decide whether the resources the IAFDemos in a namespace share can be deleted
+func (r *IAFDemoReconciler) otherIAFDemo(recctx *reconcileContext) (string, error) {
+	demos := &democartridgev1.IAFDemoList{}
+	if err := r.List(*recctx.ctx, demos, client.InNamespace(recctx.iafdemo.Namespace)); err != nil {
+		return "", fmt.Errorf("Failed to list IAFDemos in Namespace %s: %w", recctx.iafdemo.Namespace, err)
+	}
+	for _, demo := range demos.Items {
+		if demo.Name != recctx.iafdemo.Name && demo.DeletionTimestamp.IsZero() {
+			return demo.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -29,35 +29,44 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	knkafkachannel "knative.dev/eventing-contrib/kafka/channel/pkg/apis/messaging/v1alpha1"
+	knkafkasource "knative.dev/eventing-contrib/kafka/source/pkg/apis/sources/v1alpha1"
+	kneventing "knative.dev/eventing/pkg/apis/eventing/v1"
+	knmessaging "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	aiv1 "github.ibm.com/automation-base-pak/abp-ai-operator/api/v1alpha1"
 	basev1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1"
 	kafkav1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1kafka"
 	corev1beta1 "github.ibm.com/automation-base-pak/abp-core-operator/api/v1beta1"
 	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/config"
+	epv1alpha1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1alpha1"
+	epv1beta1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1beta1"
 )
 
 const (
-	deployedServerName              = "demoserver"
-	deployedProducerName            = "demoproducer"
-	iafCartridgeInstanceName        = "iafdemo"
-	iafCartridgeReqInstanceName     = "iaf-cartridgerequirements-instance"
-	automationBaseInstanceName      = "iaf-automationbase-instance"
-	eventProcessorName              = "iaf-eventprocessor-instance"
-	eventProcessingTaskInstanceName = "iaf-eventprocessing-task-instance"
-	eventProcessorInputTopic        = iafCartridgeInstanceName + "-raw"
-	eventProcessorRiskTopic         = iafCartridgeInstanceName + "-anomaly"
-	eventProcessorGroup             = iafCartridgeInstanceName + "-flink-processor"
+	producerFunction           = "producer"
+	serverFunction             = "server"
+	automationBaseInstanceName = "iaf-automationbase-instance"
 
 	eventStreamInstance = "iaf-eventstream-"
 
@@ -70,15 +79,21 @@ const (
 // IAFDemoReconciler reconciles a IAFDemo object
 type IAFDemoReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	Cfg    *config.Config
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Cfg      *config.Config
+	Recorder record.EventRecorder
+	// RESTMapper tells which optional APIs, such as those of Knative, the cluster serves
+	RESTMapper meta.RESTMapper
 }
 
 type reconcileContext struct {
 	ctx     *context.Context
 	req     *ctrl.Request
 	iafdemo *democartridgev1.IAFDemo
+	names   childNames
+	// modelVerification is the outcome of the inference smoke test, reported by the ModelVerified stage
+	modelVerification string
 }
 
 // +kubebuilder:rbac:groups=democartridge.ibm.com,resources=iafdemoes,verbs=get;list;watch;create;update;patch;delete
@@ -86,12 +101,13 @@ type reconcileContext struct {
 // +kubebuilder:rbac:groups=democartridge.ibm.com,resources=iafdemoes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.automation.ibm.com,resources=cartridges,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=base.automation.ibm.com,resources=automationbases,verbs=get;list;watch;create;update;patch;delete
 
-// +kubebuilder:rbac:groups=ibmevents.ibm.com,resources=kafkatopics,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ibmevents.ibm.com,resources=kafkatopics;kafkausers,verbs=get;list;watch;create;update;patch;delete
 
 // +kubebuilder:rbac:groups=base.automation.ibm.com,resources=cartridgerequirements,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=eventprocessing.automation.ibm.com,resources=eventprocessors,verbs=get;list;watch;create;update;patch;delete
@@ -99,7 +115,9 @@ type reconcileContext struct {
 // +kubebuilder:rbac:groups=automation.ibm.com,resources=eventprocessors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ai.automation.ibm.com,resources=aimodels,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sources.knative.dev,resources=kafkasources,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=messaging.knative.dev,resources=inmemorychannels;subscriptions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=messaging.knative.dev,resources=inmemorychannels;kafkachannels;subscriptions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=eventing.knative.dev,resources=brokers;triggers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch;create;update;patch;delete
 
 // +kubebuilder:rbac:groups=ai.automation.ibm.com,resources=airuntimes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ai.automation.ibm.com,resources=airuntimes/status,verbs=get;update;patch
@@ -109,14 +127,13 @@ type reconcileContext struct {
 // +kubebuilder:rbac:groups=ai.automation.ibm.com,resources=aimodels/status,verbs=get;update;patch
 
 func (r *IAFDemoReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	var retryWaitTime = 30 * time.Second
 	ctx := context.Background()
 	log := r.Log.WithValues("iafdemo", req.NamespacedName)
 	iafdemo := &democartridgev1.IAFDemo{}
 	err := r.Get(ctx, req.NamespacedName, iafdemo)
 	if err != nil && errors.IsNotFound(err) {
 		// Request object not found, could have been deleted after reconcile request.
-		// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+		// Owned objects are automatically garbage collected, and external state was cleaned up by the finalizer.
 		// Return and don't requeue
 		log.Info("automationbase resource not found. Ignoring since object must be deleted")
 		return ctrl.Result{}, nil
@@ -130,132 +147,102 @@ func (r *IAFDemoReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		ctx:     &ctx,
 		req:     &req,
 		iafdemo: iafdemo,
+		names:   newChildNames(iafdemo.Name),
 	}
 
-	// handle cartridge CR
-	//log.Info("Creating the Cartridge CR and Issuing it")
-	retryAfter, err := r.createCartridge(recctx)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if retryAfter {
-		log.Info("Waiting for the Cartridge registration to complete")
-		return ctrl.Result{RequeueAfter: retryWaitTime}, nil
+	if !iafdemo.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(recctx)
 	}
-
-	retryAfter, err = r.createAutomationBase(recctx)
-	if err != nil {
+	if err = r.addFinalizer(recctx); err != nil {
 		return ctrl.Result{}, err
 	}
-	if retryAfter {
-		log.Info("Waiting for the AutomationBase installation to complete")
-		return ctrl.Result{RequeueAfter: retryWaitTime}, nil
-	}
-
-	retryAfter, err = r.createCartridgeRequirements(recctx)
-	if err != nil {
+	if err = r.migrateLegacyChildren(recctx); err != nil {
 		return ctrl.Result{}, err
 	}
-	if retryAfter {
-		log.Info("Waiting for the CartridgeRequirements registration to complete")
-		return ctrl.Result{RequeueAfter: retryWaitTime}, nil
-	}
 
-	retryAfter, err = r.setupAIModels(recctx)
-	if err != nil {
-		log.Error(err, "AI is not configured")
-	}
-	if retryAfter {
-		log.Info("Waiting for the setting up of AIModels to complete")
-		return ctrl.Result{RequeueAfter: retryWaitTime}, nil
-	}
+	recctx.initStageConditions()
 
-	retryAfter, err = r.createEventStream(recctx, eventProcessorInputTopic) // demo raw
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if retryAfter {
-		log.Info("Waiting for the EventStream registration to complete")
-		return ctrl.Result{RequeueAfter: retryWaitTime}, nil
-	}
+	result, err := r.runStages(recctx, r.stages())
 
-	retryAfter, err = r.createEventStream(recctx, eventProcessorRiskTopic) // demo anomaly
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if retryAfter {
-		log.Info("Waiting for the EventStream registration to complete")
-		return ctrl.Result{RequeueAfter: retryWaitTime}, nil
-	}
-
-	err = r.reconcileEventProcessor(recctx)
-	if err != nil {
-		log.Error(err, "Failed to reconcile Event Processing CR")
-		return ctrl.Result{}, err
-	}
-
-	err = r.initializeElasticsearchIndices(recctx)
-	if err != nil {
-		log.Error(err, "Failed to initialize Elasticsearch Indices")
-		return ctrl.Result{}, err
-	}
-
-	err = r.reconcileEventProcessingTask(recctx)
-	if err != nil {
-		log.Error(err, "Failed to reconcile Event Processing Task CR")
-		return ctrl.Result{}, err
-	}
-
-	// The Knative piece can be enabled/disabled via an environment variable
-	if strings.EqualFold(r.Cfg.UseKnative, "true") {
-		err = r.reconcileKnative(recctx, deployedServerName)
-		if err != nil {
-			log.Error(err, "Failed to create Knative connection")
-			return ctrl.Result{}, err
+	// Record the outcome of every stage on the IAFDemo, whether or not the reconcile completed
+	if statusErr := r.updateStatus(recctx, result, err); statusErr != nil {
+		log.Error(statusErr, "Failed to update IAFDemo status")
+		if err == nil {
+			return ctrl.Result{}, statusErr
 		}
 	}
-
-	// create producer microservice M1
-	err = r.reconcileMicroservice(recctx, deployedProducerName)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-
-	// create server microservice M2
-	err = r.reconcileMicroservice(recctx, deployedServerName)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-
-	log.Info("Reconcile at end returning nil err or SUCESS!!")
-	return ctrl.Result{}, nil
+	return result, err
 }
 
 func (r *IAFDemoReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&democartridgev1.IAFDemo{}).
-		Complete(r)
+	owned := builder.WithPredicates(statusOrSpecChangedPredicate)
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&democartridgev1.IAFDemo{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&corev1beta1.Cartridge{}, owned).
+		Owns(&basev1beta1.CartridgeRequirements{}, owned).
+		Owns(&aiv1.AIDeployment{}, owned).
+		Owns(&kafkav1beta1.KafkaTopic{}, owned).
+		Owns(newKafkaUserObject("", ""), owned).
+		Owns(&epv1beta1.EventProcessor{}, owned).
+		Owns(&epv1alpha1.EventProcessingTask{}, owned).
+		Owns(&appsv1.Deployment{}, owned).
+		Owns(&corev1.Service{}, owned).
+		Owns(&routev1.Route{}, owned).
+		// The AutomationBase is shared by every IAFDemo in the namespace and owned by none of them
+		Watches(&source.Kind{Type: &basev1beta1.AutomationBase{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForNamespace)},
+			owned).
+		// The user operator owns the Secret with the password of the KafkaUser, which the KafkaSource gets a copy of
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForKafkaUserSecret)}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(retryBaseDelay, retryMaxDelay),
+		})
+
+	// spec.components.knative can enable Knative for a single IAFDemo whatever USE_KNATIVE says, so every Knative
+	// kind the cluster serves is watched. The Knative CRDs are not required; without a RESTMapper to tell whether
+	// they are installed, the kinds are only watched when USE_KNATIVE is true.
+	if r.RESTMapper != nil || strings.EqualFold(r.Cfg.UseKnative, "true") {
+		knativeKinds := []runtime.Object{
+			&knkafkasource.KafkaSource{},
+			&knmessaging.InMemoryChannel{},
+			&knkafkachannel.KafkaChannel{},
+			&knmessaging.Subscription{},
+			&kneventing.Broker{},
+			&kneventing.Trigger{},
+			newKnativeServiceObject("", ""),
+		}
+		for _, obj := range knativeKinds {
+			if err := r.checkKnativeServed([]runtime.Object{obj}); err != nil {
+				r.Log.Info("Not watching a Knative kind", "reason", err.Error())
+				continue
+			}
+			bldr = bldr.Owns(obj, owned)
+		}
+	}
+	return bldr.Complete(r)
 }
 
-func (r *IAFDemoReconciler) createCartridge(recctx *reconcileContext) (bool, error) {
-	namespace := recctx.iafdemo.Namespace
-	err := r.createCartridgeInstance(recctx)
-	if err != nil {
-		return false, err
+// applyCartridge registers the Cartridge. Without the license the Cartridge would never become Ready,
+// so the stage fails rather than waits on it.
+func (r *IAFDemoReconciler) applyCartridge(recctx *reconcileContext) error {
+	if !bool(recctx.iafdemo.Spec.License.Accept) {
+		return fmt.Errorf("The license terms at http://ibm.biz/IAF-license must be accepted in spec.license.accept")
 	}
+	return r.createCartridgeInstance(recctx)
+}
 
+// cartridgeReady reports whether the Cartridge has a Ready condition that is True
+func (r *IAFDemoReconciler) cartridgeReady(recctx *reconcileContext) (bool, error) {
+	namespace := recctx.iafdemo.Namespace
 	existingIafCartridgeInstance := &corev1beta1.Cartridge{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeInstanceName, Namespace: namespace}, existingIafCartridgeInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridge, Namespace: namespace}, existingIafCartridgeInstance)
 	if err != nil {
 		return false, err
 	}
 
 	runningCondition := existingIafCartridgeInstance.Status.Conditions.GetCondition("Ready")
-	if runningCondition != nil && runningCondition.Status == corev1.ConditionTrue {
-		return false, nil
-	} else {
-		return true, nil
-	}
+	return runningCondition != nil && runningCondition.Status == corev1.ConditionTrue, nil
 }
 
 func (r *IAFDemoReconciler) createCartridgeInstance(recctx *reconcileContext) error {
@@ -263,9 +250,9 @@ func (r *IAFDemoReconciler) createCartridgeInstance(recctx *reconcileContext) er
 	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
 
 	existingCartridgeInstance := &corev1beta1.Cartridge{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeInstanceName, Namespace: namespace}, existingCartridgeInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridge, Namespace: namespace}, existingCartridgeInstance)
 	if err != nil && errors.IsNotFound(err) {
-		cartridgeInstance := newCartridgeInstance(namespace, licenseAccept)
+		cartridgeInstance := newCartridgeInstance(recctx.names, namespace, licenseAccept)
 		err = ctrl.SetControllerReference(recctx.iafdemo, cartridgeInstance, r.Scheme)
 		if err != nil {
 			return fmt.Errorf("Failed to set controller reference: %s", err)
@@ -274,16 +261,17 @@ func (r *IAFDemoReconciler) createCartridgeInstance(recctx *reconcileContext) er
 		if err != nil {
 			return fmt.Errorf("Failed to create automationbase cartridge instance: %s", err)
 		}
+		r.recordCreated(recctx, "Cartridge", cartridgeInstance.Name)
 	} else if err != nil {
 		return fmt.Errorf("Failed to get automationbase cartridge instance: %s", err)
 	}
 	return nil
 }
 
-func newCartridgeInstance(namespace string, licenseAccept bool) *corev1beta1.Cartridge {
+func newCartridgeInstance(names childNames, namespace string, licenseAccept bool) *corev1beta1.Cartridge {
 	return &corev1beta1.Cartridge{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      iafCartridgeInstanceName,
+			Name:      names.cartridge,
 			Namespace: namespace,
 		},
 		Spec: corev1beta1.CartridgeSpec{
@@ -295,27 +283,22 @@ func newCartridgeInstance(namespace string, licenseAccept bool) *corev1beta1.Car
 	}
 }
 
-func (r *IAFDemoReconciler) createAutomationBase(recctx *reconcileContext) (bool, error) {
+// automationBaseReady reports whether the AutomationBase has a Ready condition that is True
+func (r *IAFDemoReconciler) automationBaseReady(recctx *reconcileContext) (bool, error) {
 	namespace := recctx.iafdemo.Namespace
-	err := r.createAutomationBaseInstance(recctx)
-	if err != nil {
-		return false, err
-	}
-
 	existingAutomationBaseInstance := &basev1beta1.AutomationBase{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: automationBaseInstanceName, Namespace: namespace}, existingAutomationBaseInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: automationBaseInstanceName, Namespace: namespace}, existingAutomationBaseInstance)
 	if err != nil {
 		return false, err
 	}
 
 	runningCondition := existingAutomationBaseInstance.Status.Conditions.GetCondition("Ready")
-	if runningCondition != nil && runningCondition.Status == corev1.ConditionTrue {
-		return false, nil
-	} else {
-		return true, nil
-	}
+	return runningCondition != nil && runningCondition.Status == corev1.ConditionTrue, nil
 }
 
+// createAutomationBaseInstance creates the AutomationBase that every IAFDemo in the namespace shares. It carries no
+// owner reference, so deleting one IAFDemo does not take it away from the others; the finalizer deletes it together
+// with the last IAFDemo instead. Owner references set by earlier operator versions are removed.
 func (r *IAFDemoReconciler) createAutomationBaseInstance(recctx *reconcileContext) error {
 	namespace := recctx.iafdemo.Namespace
 	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
@@ -323,19 +306,31 @@ func (r *IAFDemoReconciler) createAutomationBaseInstance(recctx *reconcileContex
 	err := r.Get(*recctx.ctx, types.NamespacedName{Name: automationBaseInstanceName, Namespace: namespace}, existingAutomationBaseInstance)
 	if err != nil && errors.IsNotFound(err) {
 		automationBaseInstance := newAutomationBaseInstance(namespace, licenseAccept)
-		err = ctrl.SetControllerReference(recctx.iafdemo, automationBaseInstance, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
-		}
-
 		err = r.Create(*recctx.ctx, automationBaseInstance)
 		if err != nil {
 			return fmt.Errorf("Failed to create AutomationBase instance: %s", err)
 		}
+		r.recordCreated(recctx, "AutomationBase", automationBaseInstance.Name)
+		return nil
 	} else if err != nil {
 		return fmt.Errorf("Failed to get AutomationBase instance: %s", err)
 	}
-	return nil
+	return r.removeIAFDemoOwners(recctx, existingAutomationBaseInstance)
+}
+
+// deleteSharedResources removes the AIModels no other IAFDemo in the namespace lists, then the AutomationBase and
+// the AIRuntime shared by the IAFDemos in the namespace, once no other IAFDemo uses them
+func (r *IAFDemoReconciler) deleteSharedResources(recctx *reconcileContext) error {
+	if err := r.deleteRemovedAIModels(recctx, nil); err != nil {
+		return err
+	}
+	if other, err := r.otherIAFDemo(recctx); err != nil || other != "" {
+		return err
+	}
+	namespace := recctx.iafdemo.Namespace
+	return r.deleteAll(recctx,
+		&aiv1.AIRuntime{ObjectMeta: metav1.ObjectMeta{Name: aiKFServingRuntime, Namespace: namespace}},
+		&basev1beta1.AutomationBase{ObjectMeta: metav1.ObjectMeta{Name: automationBaseInstanceName, Namespace: namespace}})
 }
 
 func newAutomationBaseInstance(namespace string, licenseAccept bool) *basev1beta1.AutomationBase {
@@ -356,34 +351,26 @@ func newAutomationBaseInstance(namespace string, licenseAccept bool) *basev1beta
 	}
 }
 
-func (r *IAFDemoReconciler) createCartridgeRequirements(recctx *reconcileContext) (bool, error) {
+// cartridgeRequirementsReady reports whether the CartridgeRequirements has a Ready condition that is True
+func (r *IAFDemoReconciler) cartridgeRequirementsReady(recctx *reconcileContext) (bool, error) {
 	namespace := recctx.iafdemo.Namespace
-	err := r.createCartridgeRequirementsInstance(recctx)
-	if err != nil {
-		return false, err
-	}
-
 	existingCartridgeReqInstance := &basev1beta1.CartridgeRequirements{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeReqInstanceName, Namespace: namespace}, existingCartridgeReqInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridgeRequirements, Namespace: namespace}, existingCartridgeReqInstance)
 	if err != nil {
 		return false, err
 	}
 
 	runningCondition := existingCartridgeReqInstance.Status.Conditions.GetCondition("Ready")
-	if runningCondition != nil && runningCondition.Status == corev1.ConditionTrue {
-		return false, nil
-	} else {
-		return true, nil
-	}
+	return runningCondition != nil && runningCondition.Status == corev1.ConditionTrue, nil
 }
 
 func (r *IAFDemoReconciler) createCartridgeRequirementsInstance(recctx *reconcileContext) error {
 	namespace := recctx.iafdemo.Namespace
 	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
 	existingCartridgeReqInstance := &basev1beta1.CartridgeRequirements{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeReqInstanceName, Namespace: namespace}, existingCartridgeReqInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridgeRequirements, Namespace: namespace}, existingCartridgeReqInstance)
 	if err != nil && errors.IsNotFound(err) {
-		cartridgeReqInstance := newCartridgeRequirementsInstance(namespace, licenseAccept)
+		cartridgeReqInstance := newCartridgeRequirementsInstance(recctx.names, namespace, licenseAccept)
 		err = ctrl.SetControllerReference(recctx.iafdemo, cartridgeReqInstance, r.Scheme)
 		if err != nil {
 			return fmt.Errorf("Failed to set controller reference: %s", err)
@@ -392,19 +379,20 @@ func (r *IAFDemoReconciler) createCartridgeRequirementsInstance(recctx *reconcil
 		if err != nil {
 			return fmt.Errorf("Failed to create cartridge requirements instance: %s", err)
 		}
+		r.recordCreated(recctx, "CartridgeRequirements", cartridgeReqInstance.Name)
 	} else if err != nil {
 		return fmt.Errorf("Failed to get cartridge requirements instance: %s", err)
 	}
 	return nil
 }
 
-func newCartridgeRequirementsInstance(namespace string, licenseAccept bool) *basev1beta1.CartridgeRequirements {
+func newCartridgeRequirementsInstance(names childNames, namespace string, licenseAccept bool) *basev1beta1.CartridgeRequirements {
 	return &basev1beta1.CartridgeRequirements{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      iafCartridgeReqInstanceName,
+			Name:      names.cartridgeRequirements,
 			Namespace: namespace,
 			Annotations: map[string]string{
-				"com.ibm.automation.cartridge": iafCartridgeInstanceName,
+				"com.ibm.automation.cartridge": names.cartridge,
 			},
 		},
 		Spec: basev1beta1.CartridgeRequirementsSpec{
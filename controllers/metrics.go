@@ -0,0 +1,100 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics of the IAFDemo pipeline, served with the controller-runtime metrics on the manager's MetricsBindAddress
+var (
+	// stageDuration is the time taken to apply a stage and check its readiness
+	stageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "iafdemo_stage_duration_seconds",
+		Help:    "Time taken to apply one stage of an IAFDemo and check its readiness.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"stage"})
+
+	// stageWaits counts the times a stage was held back, either by a dependency or by its own resources not being Ready yet
+	stageWaits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iafdemo_stage_waits_total",
+		Help: "Number of times a stage of an IAFDemo waited, by the stage it waited for.",
+	}, []string{"stage", "dependency"})
+
+	// requeues counts the reconciles that were requeued, by the stage whose resources were not Ready yet
+	requeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iafdemo_requeues_total",
+		Help: "Number of IAFDemo reconciles requeued, by the stage that was not Ready yet.",
+	}, []string{"dependency"})
+
+	// childReady is 1 for a stage whose resources are Ready and 0 otherwise. Disabled stages have no series.
+	childReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "iafdemo_child_ready",
+		Help: "Whether the resources of a stage of an IAFDemo are Ready (1) or not (0).",
+	}, []string{"namespace", "iafdemo", "stage"})
+
+	// elasticsearchFailures counts the failed Elasticsearch requests. Requests that got no response are counted as "error".
+	elasticsearchFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iafdemo_elasticsearch_request_failures_total",
+		Help: "Number of failed Elasticsearch requests, by HTTP status code.",
+	}, []string{"code"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(stageDuration, stageWaits, requeues, childReady, elasticsearchFailures)
+}
+
+// observeStageOutcome sets the readiness gauge of a stage of the IAFDemo being reconciled
+func observeStageOutcome(recctx *reconcileContext, stageName string, outcome stageOutcome) {
+	labels := []string{recctx.iafdemo.Namespace, recctx.iafdemo.Name, stageName}
+	switch outcome {
+	case outcomeReady:
+		childReady.WithLabelValues(labels...).Set(1)
+	case outcomeDisabled:
+		childReady.DeleteLabelValues(labels...)
+	default:
+		childReady.WithLabelValues(labels...).Set(0)
+	}
+}
+
+// forgetStages drops the readiness gauges of an IAFDemo that has been deleted
+func forgetStages(recctx *reconcileContext, stages []stage) {
+	for _, s := range stages {
+		childReady.DeleteLabelValues(recctx.iafdemo.Namespace, recctx.iafdemo.Name, s.name)
+	}
+}
+
+// countElasticsearchFailure counts a failed Elasticsearch request. A statusCode of 0 means there was no response.
+func countElasticsearchFailure(statusCode int) {
+	code := "error"
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	elasticsearchFailures.WithLabelValues(code).Inc()
+}
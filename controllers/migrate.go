@@ -0,0 +1,140 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	knkafkasource "knative.dev/eventing-contrib/kafka/source/pkg/apis/sources/v1alpha1"
+	knmessaging "knative.dev/eventing/pkg/apis/messaging/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	basev1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1"
+	kafkatopics "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1kafka"
+	corev1beta1 "github.ibm.com/automation-base-pak/abp-core-operator/api/v1beta1"
+	epv1alpha1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1alpha1"
+	epv1beta1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1beta1"
+)
+
+// legacyChildrenMigratedAnnotation marks an IAFDemo whose children under the fixed names of earlier operator
+// versions have been dealt with, so that migrateLegacyChildren only looks for them once
+const legacyChildrenMigratedAnnotation = "democartridge.ibm.com/legacy-children-migrated"
+
+// legacyChildren returns the children that operator versions before the names were derived from the IAFDemo
+// created under fixed names. The AIDeployment of that time is removed by deleteRemovedAIModels, its AIModel,
+// already named after the stored model, is taken over as a shared one, and the Kafka topics returned by
+// legacyTopics and the Elasticsearch indices are left alone since they hold the data produced so far.
+func legacyChildren(namespace string) []runtime.Object {
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace}
+	}
+	return []runtime.Object{
+		&corev1beta1.Cartridge{ObjectMeta: objectMeta("iafdemo")},
+		&basev1beta1.CartridgeRequirements{ObjectMeta: objectMeta("iaf-cartridgerequirements-instance")},
+		&epv1alpha1.EventProcessingTask{ObjectMeta: objectMeta("iaf-eventprocessing-task-instance")},
+		&epv1beta1.EventProcessor{ObjectMeta: objectMeta("iaf-eventprocessor-instance")},
+		&knmessaging.Subscription{ObjectMeta: objectMeta("demoserver")},
+		&knmessaging.InMemoryChannel{ObjectMeta: objectMeta("demoserver")},
+		&knkafkasource.KafkaSource{ObjectMeta: objectMeta("demoserver")},
+		&routev1.Route{ObjectMeta: objectMeta("demoserver")},
+		&corev1.Service{ObjectMeta: objectMeta("demoserver")},
+		&appsv1.Deployment{ObjectMeta: objectMeta("demoserver")},
+		&routev1.Route{ObjectMeta: objectMeta("demoproducer")},
+		&corev1.Service{ObjectMeta: objectMeta("demoproducer")},
+		&appsv1.Deployment{ObjectMeta: objectMeta("demoproducer")},
+	}
+}
+
+// legacyTopics returns the Kafka topics earlier operator versions created under fixed names
+func legacyTopics(namespace string) []runtime.Object {
+	return []runtime.Object{
+		&kafkatopics.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: "iafdemo-raw", Namespace: namespace}},
+		&kafkatopics.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: "iafdemo-anomaly", Namespace: namespace}},
+	}
+}
+
+// migrateLegacyChildren deals, once per IAFDemo, with the children it still controls under the fixed names of
+// earlier operator versions. Those that would otherwise run next to their renamed replacements until the IAFDemo
+// is deleted are removed. The Kafka topics lose their owner reference instead, so that their events are kept
+// until the user deletes them. A child whose fixed name is also the derived name, such as the Cartridge of an
+// IAFDemo named "iafdemo", is kept.
+func (r *IAFDemoReconciler) migrateLegacyChildren(recctx *reconcileContext) error {
+	if recctx.iafdemo.Annotations[legacyChildrenMigratedAnnotation] == "true" {
+		return nil
+	}
+	names := recctx.names
+	current := map[string]bool{
+		names.cartridge: true,
+		names.rawTopic:  true,
+		names.riskTopic: true,
+	}
+	for _, obj := range legacyChildren(recctx.iafdemo.Namespace) {
+		if found, err := r.getLegacyChild(recctx, obj, current); err != nil {
+			return err
+		} else if found {
+			if err := r.deleteIfExists(recctx, obj); err != nil {
+				return err
+			}
+		}
+	}
+	for _, obj := range legacyTopics(recctx.iafdemo.Namespace) {
+		if found, err := r.getLegacyChild(recctx, obj, current); err != nil {
+			return err
+		} else if found {
+			if err := r.removeIAFDemoOwners(recctx, obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	recctx.iafdemo.Annotations = mergeStringMap(recctx.iafdemo.Annotations, map[string]string{legacyChildrenMigratedAnnotation: "true"})
+	return r.Update(*recctx.ctx, recctx.iafdemo)
+}
+
+// getLegacyChild reads a child under a fixed name, reporting whether it exists, is controlled by the IAFDemo and
+// is not one of the current children
+func (r *IAFDemoReconciler) getLegacyChild(recctx *reconcileContext, obj runtime.Object, current map[string]bool) (bool, error) {
+	key, _ := client.ObjectKeyFromObject(obj)
+	if current[key.Name] {
+		return false, nil
+	}
+	err := r.Get(*recctx.ctx, key, obj)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+	return metav1.IsControlledBy(objMeta, recctx.iafdemo), nil
+}
@@ -0,0 +1,362 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// modelStore is where the AI models are kept for KFServing to load them from
+type modelStore interface {
+	// sync brings the store in line with the files bundled in dir, uploading those that are missing or differ.
+	// With prune, it also deletes the stored files that prunableModelFiles selects, leaving the model paths in
+	// keep alone.
+	sync(ctx context.Context, dir string, files []modelFile, keep []string, prune bool) (modelSyncResult, error)
+	// remove deletes the models sync stored under dir, leaving anything else in the store alone
+	remove(ctx context.Context, dir string) error
+	// storageURI returns the URI KFServing loads the model at path from
+	storageURI(path string) string
+}
+
+// modelFile is one file of the models bundled in the operator image
+type modelFile struct {
+	// path is the slash separated path of the file, which is also its path in the store
+	path string
+	size int64
+	// md5 and sha256 are the hex checksums of the content
+	md5    string
+	sha256 string
+}
+
+// modelSyncResult lists the paths sync changed in the store
+type modelSyncResult struct {
+	uploaded []string
+	pruned   []string
+}
+
+// bundledModelFiles reads the files under dir, in lexical order, with their checksums
+func bundledModelFiles(dir string) ([]modelFile, error) {
+	var files []modelFile
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		md5Hash, sha256Hash := md5.New(), sha256.New()
+		if _, err = io.Copy(io.MultiWriter(md5Hash, sha256Hash), file); err != nil {
+			return fmt.Errorf("Failed to read model file %s: %w", path, err)
+		}
+		files = append(files, modelFile{
+			path:   filepath.ToSlash(path),
+			size:   info.Size(),
+			md5:    hex.EncodeToString(md5Hash.Sum(nil)),
+			sha256: hex.EncodeToString(sha256Hash.Sum(nil)),
+		})
+		return nil
+	})
+	return files, err
+}
+
+// modelDigest returns the SHA-256 digest of the files under the model directory path, over their paths and
+// checksums, so that it changes whenever any file of the model is added, removed or changed. It is empty for
+// models that are not bundled with the operator.
+func modelDigest(files []modelFile, path string) string {
+	digest := sha256.New()
+	found := false
+	for _, file := range files {
+		if strings.HasPrefix(file.path, path+"/") {
+			fmt.Fprintf(digest, "%s %s\n", file.sha256, file.path)
+			found = true
+		}
+	}
+	if !found {
+		return ""
+	}
+	return "sha256:" + hex.EncodeToString(digest.Sum(nil))
+}
+
+// prunableModelFiles returns, in lexical order, the stored paths that pruning deletes: those inside the directory
+// of a bundled model that are no longer bundled. Models that are not bundled, such as the ones put in the store by
+// hand for spec.models, are never pruned, and neither are the model paths in keep that lie inside or outside the
+// bundled model directories. A path in keep that is the directory of a bundled model, as for the default model,
+// does not stop its old files from being pruned.
+func prunableModelFiles(stored []string, files []modelFile, keep []string) []string {
+	bundled := map[string]bool{}
+	modelDirs := map[string]bool{}
+	for _, file := range files {
+		bundled[file.path] = true
+		// The model directory is the first level below the models directory, such as models/anomaly-classifier
+		if parts := strings.SplitN(file.path, "/", 3); len(parts) == 3 {
+			modelDirs[parts[0]+"/"+parts[1]] = true
+		}
+	}
+	var kept []string
+	for _, path := range keep {
+		path = strings.TrimSuffix(path, "/")
+		if !modelDirs[path] {
+			kept = append(kept, path)
+		}
+	}
+	var prunable []string
+	for _, path := range stored {
+		parts := strings.SplitN(path, "/", 3)
+		if bundled[path] || len(parts) < 3 || !modelDirs[parts[0]+"/"+parts[1]] || underModelPath(path, kept) {
+			continue
+		}
+		prunable = append(prunable, path)
+	}
+	sort.Strings(prunable)
+	return prunable
+}
+
+func underModelPath(path string, modelPaths []string) bool {
+	for _, modelPath := range modelPaths {
+		if path == modelPath || strings.HasPrefix(path, modelPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// newModelStore connects to the model store selected by spec.modelStore
+func (r *IAFDemoReconciler) newModelStore(recctx *reconcileContext) (modelStore, error) {
+	return r.newS3ModelStore(recctx, recctx.iafdemo.Spec.ModelStore.S3)
+}
+
+// newTLSTransport returns an HTTP transport that also trusts the CA in the given Secret key, if there is one
+func (r *IAFDemoReconciler) newTLSTransport(recctx *reconcileContext, ca *corev1.SecretKeySelector) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if ca == nil {
+		return transport, nil
+	}
+	namespace := recctx.iafdemo.Namespace
+	caSecret := &corev1.Secret{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: ca.Name, Namespace: namespace}, caSecret)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CA Secret %s in Namespace %s: %w", ca.Name, namespace, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(caSecret.Data[ca.Key]) {
+		return nil, fmt.Errorf("Failed to read PEM certificates from key '%s' in Secret %s in Namespace %s", ca.Key, ca.Name, namespace)
+	}
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport, nil
+}
+
+// s3ModelStore uploads the models to a bucket of an S3 compatible object store, such as MinIO
+type s3ModelStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// newS3ModelStore connects to the object store described by the credentials Secret of the S3 settings
+func (r *IAFDemoReconciler) newS3ModelStore(recctx *reconcileContext, settings democartridgev1.S3ModelStore) (*s3ModelStore, error) {
+	namespace := recctx.iafdemo.Namespace
+	secretName := settings.SecretNameOrDefault()
+	authSecret := &corev1.Secret{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, authSecret)
+	if err != nil && errors.IsNotFound(err) {
+		err = fmt.Errorf("Failed to find Secret %s in Namespace %s: %w", secretName, namespace, err)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	username, ok := authSecret.Data["AWS_ACCESS_KEY_ID"]
+	if !ok {
+		return nil, fmt.Errorf("Failed to get key 'AWS_ACCESS_KEY_ID' in Secret %s in Namespace %s", secretName, namespace)
+	}
+	accessKeyID := strings.TrimSuffix(string(username), "\n")
+	password, ok := authSecret.Data["AWS_SECRET_ACCESS_KEY"]
+	if !ok {
+		return nil, fmt.Errorf("Failed to get key 'AWS_SECRET_ACCESS_KEY' in Secret %s in Namespace %s", secretName, namespace)
+	}
+	secretAccessKey := strings.TrimSuffix(string(password), "\n")
+	url, ok := authSecret.Annotations["serving.kubeflow.org/s3-endpoint"]
+	if !ok {
+		return nil, fmt.Errorf("Failed to get endpoint annotation in Secret %s in Namespace %s", secretName, namespace)
+	}
+	endpoint := strings.TrimSuffix(string(url), "\n")
+
+	// Follow the KFServing annotation unless the IAFDemo says otherwise, so the operator and KFServing agree
+	useSSL := authSecret.Annotations["serving.kubeflow.org/s3-usehttps"] == "1"
+	if settings.TLS != nil {
+		useSSL = *settings.TLS
+	}
+	transport, err := r.newTLSTransport(recctx, settings.CA)
+	if err != nil {
+		return nil, err
+	}
+	minioClient, err := minio.New(endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure:    useSSL,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to the S3 endpoint %s: %w", endpoint, err)
+	}
+	return &s3ModelStore{client: minioClient, bucket: settings.BucketOrDefault()}, nil
+}
+
+// sync creates the bucket if needed and uploads the files whose size or checksum differs from the stored object
+func (s *s3ModelStore) sync(ctx context.Context, dir string, files []modelFile, keep []string, prune bool) (modelSyncResult, error) {
+	result := modelSyncResult{}
+	found, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return result, fmt.Errorf("Failed to look up bucket %s: %w", s.bucket, err)
+	}
+	if !found {
+		if err = s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{}); err != nil {
+			return result, fmt.Errorf("Failed to create bucket %s: %w", s.bucket, err)
+		}
+	}
+
+	stored := map[string]minio.ObjectInfo{}
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: dir + "/", Recursive: true}) {
+		if object.Err != nil {
+			return result, fmt.Errorf("Failed to list bucket %s: %w", s.bucket, object.Err)
+		}
+		stored[object.Key] = object
+	}
+
+	for _, file := range files {
+		object, found := stored[file.path]
+		delete(stored, file.path)
+		if found {
+			same, err := s.sameContent(ctx, object, file)
+			if err != nil {
+				return result, err
+			}
+			if same {
+				continue
+			}
+		}
+		if err = s.put(ctx, file); err != nil {
+			return result, err
+		}
+		result.uploaded = append(result.uploaded, file.path)
+	}
+
+	if !prune {
+		return result, nil
+	}
+	var storedKeys []string
+	for key := range stored {
+		storedKeys = append(storedKeys, key)
+	}
+	for _, key := range prunableModelFiles(storedKeys, files, keep) {
+		if err = s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+			return result, fmt.Errorf("Failed to delete object %s from bucket %s: %w", key, s.bucket, err)
+		}
+		result.pruned = append(result.pruned, key)
+	}
+	return result, nil
+}
+
+// sameContent compares a stored object with a bundled file. The ETag of an object uploaded in one part is the
+// MD5 of its content; for one uploaded in parts, the SHA-256 recorded in its metadata by put is compared instead.
+func (s *s3ModelStore) sameContent(ctx context.Context, object minio.ObjectInfo, file modelFile) (bool, error) {
+	if object.Size != file.size {
+		return false, nil
+	}
+	etag := strings.Trim(object.ETag, "\"")
+	if !strings.Contains(etag, "-") {
+		return etag == file.md5, nil
+	}
+	info, err := s.client.StatObject(ctx, s.bucket, object.Key, minio.StatObjectOptions{})
+	if err != nil {
+		return false, fmt.Errorf("Failed to read object %s in bucket %s: %w", object.Key, s.bucket, err)
+	}
+	return info.UserMetadata["Sha256"] == file.sha256, nil
+}
+
+// put uploads a bundled file, recording its SHA-256 in the object metadata
+func (s *s3ModelStore) put(ctx context.Context, file modelFile) error {
+	content, err := os.Open(filepath.FromSlash(file.path))
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	_, err = s.client.PutObject(ctx, s.bucket, file.path, content, file.size, minio.PutObjectOptions{
+		ContentType:  "application/octet-stream",
+		UserMetadata: map[string]string{"Sha256": file.sha256},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to upload %s to bucket %s: %w", file.path, s.bucket, err)
+	}
+	return nil
+}
+
+// remove deletes the objects under dir. The bucket is kept, since it may hold other data or be provided by an admin.
+func (s *s3ModelStore) remove(ctx context.Context, dir string) error {
+	found, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil || !found {
+		return err
+	}
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectsCh)
+		for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: dir + "/", Recursive: true}) {
+			if object.Err == nil {
+				objectsCh <- object
+			}
+		}
+	}()
+	for removeErr := range s.client.RemoveObjects(ctx, s.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		err = fmt.Errorf("Failed to delete object %s from bucket %s: %w", removeErr.ObjectName, s.bucket, removeErr.Err)
+	}
+	return err
+}
+
+func (s *s3ModelStore) storageURI(path string) string {
+	return "s3://" + s.bucket + "/" + path
+}
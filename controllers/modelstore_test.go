@@ -0,0 +1,56 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrunableModelFiles(t *testing.T) {
+	files := []modelFile{
+		{path: "models/anomaly-classifier/2/saved_model.pb"},
+		{path: "models/anomaly-classifier/2/variables/variables.index"},
+	}
+	stored := []string{
+		"models/anomaly-classifier/2/saved_model.pb",
+		"models/anomaly-classifier/1/saved_model.pb",
+		"models/anomaly-classifier/1/variables/variables.index",
+		"models/anomaly-classifier/3/saved_model.pb",
+		"models/fraud-scorer/1/saved_model.pb",
+		"models/README",
+	}
+	// The directory of the bundled model does not keep its older versions, unlike the other paths in keep
+	keep := []string{"models/anomaly-classifier", "models/fraud-scorer", "models/anomaly-classifier/3/"}
+
+	want := []string{
+		"models/anomaly-classifier/1/saved_model.pb",
+		"models/anomaly-classifier/1/variables/variables.index",
+	}
+	if got := prunableModelFiles(stored, files, keep); !reflect.DeepEqual(got, want) {
+		t.Errorf("prunableModelFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// childNames holds the names of the resources created for one IAFDemo. They are derived from
+// the name of the IAFDemo so that several demo pipelines can run side by side in a namespace.
+type childNames struct {
+	cartridge             string
+	cartridgeRequirements string
+	eventProcessor        string
+	eventProcessingTask   string
+	rawTopic              string
+	riskTopic             string
+	deadLetterTopic       string
+	alertsTopic           string
+	kafkaUser             string
+	kafkaSourceSecret     string
+	flinkGroup            string
+	flinkJobManager       string
+	flinkTLSSecret        string
+	flinkAdminSecret      string
+	rawIndex              string
+	riskIndex             string
+	instance              string
+}
+
+func newChildNames(instance string) childNames {
+	return childNames{
+		cartridge:             instance,
+		cartridgeRequirements: instance + "-cartridgerequirements",
+		eventProcessor:        instance + "-eventprocessor",
+		eventProcessingTask:   instance + "-eventprocessing-task",
+		rawTopic:              instance + "-raw",
+		riskTopic:             instance + "-anomaly",
+		deadLetterTopic:       instance + "-deadletter",
+		alertsTopic:           instance + "-alerts",
+		kafkaUser:             instance + "-kafkauser",
+		kafkaSourceSecret:     instance + "-kafkasource-auth",
+		flinkGroup:            instance + "-flink-processor",
+		flinkJobManager:       instance + "-eventprocessor-jobmanager",
+		flinkTLSSecret:        instance + "-eventprocessor-tls",
+		flinkAdminSecret:      instance + "-eventprocessor-admin",
+		rawIndex:              instance + "-raw",
+		riskIndex:             instance + "-anomaly",
+		instance:              instance,
+	}
+}
+
+// microservice returns the name of the Deployment, Service and Route for the "producer" or "server" function
+func (n childNames) microservice(function string) string {
+	return n.instance + "-demo" + function
+}
+
+// aiDeployment returns the name of the AIDeployment that serves an entry of spec.models
+func (n childNames) aiDeployment(model string) string {
+	return n.instance + "-" + model
+}
+
+// aiCanary returns the name of the AIDeployment for the candidate version of an entry of spec.models
+func (n childNames) aiCanary(model string) string {
+	return n.instance + "-" + model + democartridgev1.CanaryNameSuffix
+}
@@ -0,0 +1,236 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// errorPolicy says what the pipeline does when a stage fails
+type errorPolicy int
+
+const (
+	// abortOnError stops the pipeline and returns the error, so the IAFDemo is retried with backoff
+	abortOnError errorPolicy = iota
+	// continueOnError records the failure and carries on. Stages that depend on this one are skipped, while
+	// those that only run after it still run, so it suits stages the demo can do without.
+	continueOnError
+)
+
+// stage is one step of the IAFDemo pipeline. Each stage reports its outcome to the status condition named after it.
+type stage struct {
+	// name is the status condition type the stage reports to
+	name string
+	// dependsOn lists the stages that have to be Ready (or Disabled) before this one runs. The stage is skipped
+	// when one of them failed with continueOnError.
+	dependsOn []string
+	// after lists the stages that have to have run before this one, but whose failure with continueOnError
+	// does not hold it back
+	after []string
+	// describe names what the stage manages, for the status messages
+	describe func(recctx *reconcileContext) string
+	// enabled reports whether the stage should run, and if not, why. A nil enabled means the stage always runs.
+	enabled func(recctx *reconcileContext) (bool, string)
+	// apply creates or updates the resources of the stage
+	apply func(recctx *reconcileContext) error
+	// ready reports whether the applied resources are ready. A nil ready means the stage is Ready once applied.
+	ready func(recctx *reconcileContext) (bool, error)
+	// report, if set, replaces the message of a Ready stage, for stages that measure something worth showing
+	report func(recctx *reconcileContext) string
+	// cleanup removes what apply created, when the stage is disabled after it has run
+	cleanup func(recctx *reconcileContext) error
+	// onError is the error policy of the stage
+	onError errorPolicy
+}
+
+// stageOutcome is the result of running one stage
+type stageOutcome int
+
+const (
+	outcomeReady stageOutcome = iota
+	outcomeWaiting
+	outcomeFailed
+	outcomeDisabled
+	// outcomeSkipped is a stage that did not run because a stage it depends on failed with continueOnError
+	outcomeSkipped
+)
+
+// runStages runs the stages in order and records each one's outcome in the IAFDemo status. A stage whose
+// dependencies are not satisfied waits for them, and one whose dependencies failed is skipped. The IAFDemo is requeued while any stage is waiting,
+// and checked again after recheckDelay while a stage has failed with continueOnError.
+func (r *IAFDemoReconciler) runStages(recctx *reconcileContext, stages []stage) (ctrl.Result, error) {
+	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
+	outcomes := map[string]stageOutcome{}
+	policies := map[string]errorPolicy{}
+	waiting := false
+	tolerated := false
+
+	for _, s := range stages {
+		policies[s.name] = s.onError
+		var previous *metav1.Condition
+		if condition := meta.FindStatusCondition(recctx.iafdemo.Status.Conditions, s.name); condition != nil {
+			previousCondition := *condition
+			previous = &previousCondition
+		}
+
+		outcome, err := r.stepStage(recctx, s, outcomes, policies)
+		outcomes[s.name] = outcome
+		r.recordStageEvent(recctx, s.name, previous)
+		observeStageOutcome(recctx, s.name, outcome)
+
+		switch outcome {
+		case outcomeWaiting:
+			log.Info("Waiting", "stage", s.name)
+			waiting = true
+		case outcomeFailed:
+			log.Error(err, "Stage failed", "stage", s.name)
+			if s.onError == abortOnError {
+				return ctrl.Result{}, err
+			}
+			tolerated = true
+		}
+	}
+
+	if waiting {
+		// Count the requeue against the stages that wait for their own resources, not those held back by them
+		for _, s := range stages {
+			if outcomes[s.name] == outcomeWaiting && len(unsatisfied(s, outcomes, policies)) == 0 {
+				requeues.WithLabelValues(s.name).Inc()
+			}
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if tolerated {
+		log.Info("Stages reconciled with tolerated failures", "recheckAfter", recheckDelay)
+		return ctrl.Result{RequeueAfter: recheckDelay}, nil
+	}
+	log.Info("All stages reconciled")
+	return ctrl.Result{}, nil
+}
+
+// stepStage disables, holds back or runs one stage, depending on its switch and the outcome of its dependencies
+func (r *IAFDemoReconciler) stepStage(recctx *reconcileContext, s stage, outcomes map[string]stageOutcome, policies map[string]errorPolicy) (stageOutcome, error) {
+	if s.enabled != nil {
+		if enabled, reason := s.enabled(recctx); !enabled {
+			return r.disableStage(recctx, s, reason)
+		}
+	}
+	if blockedBy := unsatisfied(s, outcomes, policies); len(blockedBy) > 0 {
+		for _, dependency := range blockedBy {
+			stageWaits.WithLabelValues(s.name, dependency).Inc()
+		}
+		recctx.stageWaiting(s.name, "Waiting for "+strings.Join(blockedBy, ", "))
+		return outcomeWaiting, nil
+	}
+	if failed := failedDependencies(s.dependsOn, outcomes); len(failed) > 0 {
+		recctx.stageSkipped(s.name, "Skipped since "+strings.Join(failed, ", ")+" did not succeed")
+		return outcomeSkipped, nil
+	}
+	return r.runStage(recctx, s)
+}
+
+// runStage applies one stage and checks its readiness, recording the outcome in the IAFDemo status
+func (r *IAFDemoReconciler) runStage(recctx *reconcileContext, s stage) (stageOutcome, error) {
+	timer := prometheus.NewTimer(stageDuration.WithLabelValues(s.name))
+	defer timer.ObserveDuration()
+
+	if err := s.apply(recctx); err != nil {
+		recctx.stageFailed(s.name, err)
+		return outcomeFailed, err
+	}
+	if s.ready == nil {
+		message := s.describe(recctx) + " reconciled"
+		if s.report != nil {
+			message = s.report(recctx)
+		}
+		recctx.stageReady(s.name, message)
+		return outcomeReady, nil
+	}
+
+	ready, err := s.ready(recctx)
+	if err != nil {
+		err = fmt.Errorf("%s is not Ready: %w", s.describe(recctx), err)
+		recctx.stageFailed(s.name, err)
+		return outcomeFailed, err
+	}
+	if !ready {
+		recctx.stageWaiting(s.name, "Waiting for "+s.describe(recctx)+" to become Ready")
+		return outcomeWaiting, nil
+	}
+	recctx.stageReady(s.name, s.describe(recctx)+" is Ready")
+	return outcomeReady, nil
+}
+
+// disableStage reports a stage as Disabled, first cleaning up what it created if it was not already disabled
+func (r *IAFDemoReconciler) disableStage(recctx *reconcileContext, s stage, reason string) (stageOutcome, error) {
+	previous := meta.FindStatusCondition(recctx.iafdemo.Status.Conditions, s.name)
+	if s.cleanup != nil && (previous == nil || previous.Reason != democartridgev1.ReasonDisabled) {
+		if err := s.cleanup(recctx); err != nil {
+			err = fmt.Errorf("Failed to clean up %s: %w", s.describe(recctx), err)
+			recctx.stageFailed(s.name, err)
+			return outcomeFailed, err
+		}
+	}
+	recctx.stageDisabled(s.name, reason)
+	return outcomeDisabled, nil
+}
+
+// unsatisfied returns the dependencies, and the stages it runs after, that keep a stage waiting. Those that
+// failed with continueOnError, or were skipped, have run their course and do not keep it waiting.
+func unsatisfied(s stage, outcomes map[string]stageOutcome, policies map[string]errorPolicy) []string {
+	var blockedBy []string
+	for _, dependency := range append(append([]string{}, s.dependsOn...), s.after...) {
+		outcome, ran := outcomes[dependency]
+		switch {
+		case !ran:
+			blockedBy = append(blockedBy, dependency)
+		case outcome == outcomeReady, outcome == outcomeDisabled, outcome == outcomeSkipped:
+		case outcome == outcomeFailed && policies[dependency] == continueOnError:
+		default:
+			blockedBy = append(blockedBy, dependency)
+		}
+	}
+	return blockedBy
+}
+
+// failedDependencies returns the dependencies that failed or were skipped, which a stage cannot run without
+func failedDependencies(dependsOn []string, outcomes map[string]stageOutcome) []string {
+	var failed []string
+	for _, dependency := range dependsOn {
+		if outcome := outcomes[dependency]; outcome == outcomeFailed || outcome == outcomeSkipped {
+			failed = append(failed, dependency)
+		}
+	}
+	return failed
+}
@@ -26,18 +26,14 @@ limitations under the License.
 package controllers
 
 import (
-	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	aiv1 "github.ibm.com/automation-base-pak/abp-ai-operator/api/v1alpha1"
-	ctrl "sigs.k8s.io/controller-runtime"
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	// aiv1 "github.ibm.com/automation-base-pak/abp-ai-operator/api/v1alpha1"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,120 +41,185 @@ import (
 )
 
 const (
-	aiModelInstanceName        = "anomaly-classifier"
-	aiModelInstanceVersion     = "1.0.0"
-	aiAPIVersion               = "1.0.0"
-	aiModelInstanceDescription = "Sample AI Model that categorises risk"
-	aiModelInstanceType        = "tensorflow"
-	aiModelSourceSecret        = "github-secret"
-	aiModelInstancePath        = "models/anomaly-classifier"
-
-	aiModelInstanceBucket         = "iaf-ai"
-	aiModelStorageSecret          = "minio-secret"
+	aiAPIVersion        = "1.0.0"
+	aiModelSourceSecret = "github-secret"
+	// aiModelsDir holds the models bundled in the operator image, and the models in the model store, where the
+	// AI operator loads each AIModel from the directory named after it
+	aiModelsDir = "models"
+	// aiModelSharedLabel marks the AIModels the IAFDemos in the namespace share, so that the last IAFDemo that
+	// lists a model can delete its AIModel
+	aiModelSharedLabel = "democartridge.ibm.com/shared-aimodel"
+
 	aiModelKFSecret               = "kfserving-secret"
-	aiKFServingRuntime            = "kfservingruntime"
+	aiKFServingRuntime            = democartridgev1.DefaultModelRuntime
 	aiKFServingRuntimeType        = "serving"
 	aiKFServingRuntimePlatform    = "kfserving"
 	aiKFServingRuntimeDescription = "KFServing runtime to deploy models"
-
-	aiDeploymentInstanceName = "anomaly-classifier"
 )
 
-func (r *IAFDemoReconciler) setupAIModels(recctx *reconcileContext) (bool, error) {
+func (r *IAFDemoReconciler) setupAIModels(recctx *reconcileContext) error {
 	r.Log.Info("Setting up AI Custom Resources")
-	es := false
-	namespace := recctx.iafdemo.Namespace
-	authSecret := &corev1.Secret{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: aiModelStorageSecret, Namespace: namespace}, authSecret)
-	if err != nil && errors.IsNotFound(err) {
-		err = fmt.Errorf("Failed to find Secret %s in Namespace %s: %w", aiModelStorageSecret, namespace, err)
-		return es, err
-	} else if err != nil {
-		return es, err
-	}
-	username, ok := authSecret.Data["AWS_ACCESS_KEY_ID"]
-	if !ok {
-		err = fmt.Errorf("Failed to get key 'AWS_ACCESS_KEY_ID' in Secret %s in Namespace %s: %w", aiModelStorageSecret, namespace, err)
-		return es, err
-	}
-	accessKeyID := strings.TrimSuffix(string(username), "\n")
-	password, ok := authSecret.Data["AWS_SECRET_ACCESS_KEY"]
-	if !ok {
-		err = fmt.Errorf("Failed to get key 'AWS_SECRET_ACCESS_KEY' in Secret %s in Namespace %s: %w", aiModelStorageSecret, namespace, err)
-		return es, err
-	}
-	secretAccessKey := strings.TrimSuffix(string(password), "\n")
-	url, ok := authSecret.Annotations["serving.kubeflow.org/s3-endpoint"]
-	if !ok {
-		err = fmt.Errorf("Failed to get endpoint annotation in Secret %s in Namespace %s: %w", aiModelStorageSecret, namespace, err)
-		return es, err
-	}
-	endpoint := strings.TrimSuffix(string(url), "\n")
-
-	useSSL := false
-	minioClient, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: useSSL,
-	})
-
+	store, err := r.newModelStore(recctx)
 	if err != nil {
-		fmt.Println(err)
-		return false, err
+		return err
 	}
-	r.Log.Info("Copying bundled AIModels to Minio bucket")
-	found, err := minioClient.BucketExists(context.Background(), aiModelInstanceBucket)
+	files, err := bundledModelFiles(aiModelsDir)
 	if err != nil {
-		fmt.Println(err)
-		return false, err
+		return err
 	}
-
-	if !found {
-		// Create a bucket at region 'us-east-1' with object locking enabled.
-		err = minioClient.MakeBucket(context.Background(), aiModelInstanceBucket, minio.MakeBucketOptions{})
-		if err != nil {
-			fmt.Println(err)
-			return false, err
+	settings := recctx.iafdemo.Spec.ModelStore
+	r.Log.Info("Syncing bundled AIModels to the model store", "type", settings.TypeOrDefault())
+	var keep []string
+	if settings.Prune {
+		if keep, err = r.referencedModelPaths(recctx); err != nil {
+			return err
 		}
-		err = filepath.Walk("models",
-			func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-				if !info.IsDir() {
-					file, err := os.Open(path)
-					if err != nil {
-						fmt.Println(err)
-						return err
-					}
-					defer file.Close()
-					fileStat, err := file.Stat()
-					if err != nil {
-						fmt.Println(err)
-						return err
-					}
-					_, err = minioClient.PutObject(context.Background(), aiModelInstanceBucket, path, file, fileStat.Size(), minio.PutObjectOptions{ContentType: "application/octet-stream"})
-					if err != nil {
-						fmt.Println(err)
-						return err
-					}
-				}
+	}
+	result, err := store.sync(*recctx.ctx, aiModelsDir, files, keep, settings.Prune)
+	if err != nil {
+		return err
+	}
+	if len(result.uploaded) > 0 || len(result.pruned) > 0 {
+		r.Log.Info("Synced AIModels", "uploaded", result.uploaded, "pruned", result.pruned)
+		r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonModelsSynced, "Uploaded %d and pruned %d model files in the %s model store",
+			len(result.uploaded), len(result.pruned), settings.TypeOrDefault())
+	}
 
-				return nil
+	models := recctx.iafdemo.Spec.ModelsOrDefault()
+	if err := r.createAIRuntime(recctx, models); err != nil {
+		return err
+	}
+	r.Log.Info("Deploying the Models on Kubeflow")
+	recctx.iafdemo.Status.Models = nil
+	for _, model := range models {
+		status := democartridgev1.ModelStatus{
+			Name:       model.Name,
+			StorageURI: store.storageURI(aiModelPath(model.ServedModel())),
+			Digest:     modelDigest(files, aiModelPath(model.ServedModel())),
+		}
+		for _, served := range servedVersions(recctx.names, model) {
+			if err := r.deployModelOnKubeflow(recctx, model, served); err != nil {
+				return err
+			}
+			status.Versions = append(status.Versions, democartridgev1.ModelVersionStatus{
+				Version:        served.version,
+				Deployment:     served.aideployment,
+				TrafficPercent: served.trafficPercent,
 			})
-		if err != nil {
-			return false, err
 		}
+		recctx.setModelStatus(status)
+	}
+	return r.deleteRemovedAIModels(recctx, models)
+}
 
+// aiModelPath returns the directory of the model store the AI operator loads the AIModel with the given name from
+func aiModelPath(aimodel string) string {
+	return aiModelsDir + "/" + aimodel
+}
+
+// referencedModelPaths returns the paths of every model and candidate version listed by the IAFDemos in the namespace,
+// which share the model store, so that pruning never deletes a model that is in use
+func (r *IAFDemoReconciler) referencedModelPaths(recctx *reconcileContext) ([]string, error) {
+	aimodels, err := r.referencedAIModels(recctx, recctx.iafdemo.Spec.ModelsOrDefault())
+	if err != nil {
+		return nil, err
 	}
+	var paths []string
+	for aimodel := range aimodels {
+		paths = append(paths, aiModelPath(aimodel))
+	}
+	return paths, nil
+}
 
-	r.Log.Info("Deploying the Models on Kubeflow")
-	return r.deployModelOnKubeflow(recctx)
+// referencedAIModels returns the names of the AIModels for every model and candidate version listed by the IAFDemos
+// in the namespace that are not being deleted, taking models as the list of this IAFDemo
+func (r *IAFDemoReconciler) referencedAIModels(recctx *reconcileContext, models []democartridgev1.ModelSpec) (map[string]bool, error) {
+	demos := &democartridgev1.IAFDemoList{}
+	if err := r.List(*recctx.ctx, demos, client.InNamespace(recctx.iafdemo.Namespace)); err != nil {
+		return nil, fmt.Errorf("Failed to list IAFDemos in Namespace %s: %w", recctx.iafdemo.Namespace, err)
+	}
+	aimodels := map[string]bool{}
+	addModels := func(models []democartridgev1.ModelSpec) {
+		for _, model := range models {
+			aimodels[model.Name] = true
+			if model.Canary.Version != "" {
+				aimodels[model.CanaryModelOrDefault()] = true
+			}
+		}
+	}
+	addModels(models)
+	for _, demo := range demos.Items {
+		if demo.Name != recctx.iafdemo.Name && demo.DeletionTimestamp.IsZero() {
+			addModels(demo.Spec.ModelsOrDefault())
+		}
+	}
+	return aimodels, nil
 }
 
-func (r *IAFDemoReconciler) getPredictorURL(recctx *reconcileContext) (string, error) {
+// servedVersion is a version of a model with the AIModel and AIDeployment that serve it. The AIModel is named
+// after the stored model, which is how the AI operator finds it.
+type servedVersion struct {
+	aimodel        string
+	aideployment   string
+	version        string
+	trafficPercent int32
+}
+
+// servedVersions returns the versions of a model to serve: the current one and, during a canary rollout,
+// the candidate, each with its share of the events
+func servedVersions(names childNames, model democartridgev1.ModelSpec) []servedVersion {
+	versions := []servedVersion{{
+		aimodel:        model.ServedModel(),
+		aideployment:   names.aiDeployment(model.Name),
+		version:        model.ServedVersion(),
+		trafficPercent: 100,
+	}}
+	if model.CanaryActive() {
+		percent := model.Canary.TrafficPercentOrDefault()
+		versions[0].trafficPercent -= percent
+		versions = append(versions, servedVersion{
+			aimodel:        model.CanaryModelOrDefault(),
+			aideployment:   names.aiCanary(model.Name),
+			version:        model.Canary.Version,
+			trafficPercent: percent,
+		})
+	}
+	return versions
+}
+
+// modelPredictor is where the EventProcessingTask sends events to be scored: the predictor of the first model
+// and, during a canary rollout, the predictor of its candidate with the share of the events it scores
+type modelPredictor struct {
+	url           string
+	canaryURL     string
+	canaryPercent int32
+}
+
+func (r *IAFDemoReconciler) getModelPredictor(recctx *reconcileContext) (modelPredictor, error) {
 	r.Log.Info("Get the AIDeployment endpoint")
+	var predictor modelPredictor
+	versions := servedVersions(recctx.names, recctx.iafdemo.Spec.ModelsOrDefault()[0])
+	url, err := r.getPredictorURL(recctx, versions[0].aideployment)
+	if err != nil {
+		return predictor, err
+	}
+	predictor.url = url
+	if len(versions) > 1 {
+		// Until the candidate is Ready the current version scores every event
+		canaryURL, err := r.getPredictorURL(recctx, versions[1].aideployment)
+		if err != nil {
+			r.Log.Info("Candidate model is not scoring events yet", "aideployment", versions[1].aideployment, "reason", err.Error())
+			return predictor, nil
+		}
+		predictor.canaryURL = canaryURL
+		predictor.canaryPercent = versions[1].trafficPercent
+	}
+	return predictor, nil
+}
+
+func (r *IAFDemoReconciler) getPredictorURL(recctx *reconcileContext, name string) (string, error) {
 	aideployment := &aiv1.AIDeployment{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: aiDeploymentInstanceName, Namespace: recctx.iafdemo.Namespace}, aideployment)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: name, Namespace: recctx.iafdemo.Namespace}, aideployment)
 	if err == nil {
 		readyCondition := aideployment.Status.Conditions.GetCondition("Ready")
 		if readyCondition != nil {
@@ -176,51 +237,75 @@ func (r *IAFDemoReconciler) getPredictorURL(recctx *reconcileContext) (string, e
 	return "", fmt.Errorf("Inference Service Not Found")
 }
 
-func (r *IAFDemoReconciler) deployModelOnKubeflow(recctx *reconcileContext) (bool, error) {
-	//Check if AIDeployment exists
-	r.Log.Info("Checking if AIDeployment exists")
-	aideployment := &aiv1.AIDeployment{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: aiDeploymentInstanceName, Namespace: recctx.iafdemo.Namespace}, aideployment)
-	if err == nil {
-		readyCondition := aideployment.Status.Conditions.GetCondition("Ready")
+// aiDeploymentNames returns the names of the AIDeployments for spec.models, including those of candidate versions
+func aiDeploymentNames(recctx *reconcileContext) []string {
+	var names []string
+	for _, model := range recctx.iafdemo.Spec.ModelsOrDefault() {
+		for _, served := range servedVersions(recctx.names, model) {
+			names = append(names, served.aideployment)
+		}
+	}
+	return names
+}
 
-		if readyCondition != nil {
-			if readyCondition.IsUnknown() || strings.EqualFold(string(readyCondition.Reason), "Pending") {
-				r.Log.Info("UNKNOWN")
-				return true, nil
-			}
-			if readyCondition.Status == corev1.ConditionTrue {
-				if len(aideployment.Status.Endpoint) > 0 {
-					r.Log.Info(aideployment.Status.Endpoint)
-					return false, nil
-				}
-				return false, fmt.Errorf("Invalid Inference Service Endpoint")
-			} else {
-				return false, fmt.Errorf("Failed to create Inference Service: %s", readyCondition.Reason)
-			}
+func describeAIDeployments(recctx *reconcileContext) string {
+	return "AIDeployments " + strings.Join(aiDeploymentNames(recctx), ", ")
+}
+
+// aiModelsReady reports whether every AIDeployment has been served and has an endpoint, recording the
+// endpoints in the status of the models
+func (r *IAFDemoReconciler) aiModelsReady(recctx *reconcileContext) (bool, error) {
+	allReady := true
+	for _, name := range aiDeploymentNames(recctx) {
+		aideployment := &aiv1.AIDeployment{}
+		err := r.Get(*recctx.ctx, types.NamespacedName{Name: name, Namespace: recctx.iafdemo.Namespace}, aideployment)
+		if err != nil {
+			return false, err
+		}
+		readyCondition := aideployment.Status.Conditions.GetCondition("Ready")
+		if readyCondition == nil || readyCondition.IsUnknown() || strings.EqualFold(string(readyCondition.Reason), "Pending") {
+			allReady = false
+			continue
+		}
+		if readyCondition.Status != corev1.ConditionTrue {
+			return false, fmt.Errorf("Failed to create Inference Service %s: %s", name, readyCondition.Reason)
 		}
-		return true, nil
-	} else if !errors.IsNotFound(err) {
-		return false, err
+		if len(aideployment.Status.Endpoint) == 0 {
+			return false, fmt.Errorf("Invalid Inference Service Endpoint for %s", name)
+		}
+		r.Log.Info(aideployment.Status.Endpoint)
+		recctx.setModelEndpoint(name, aideployment.Status.Endpoint)
+	}
+	return allReady, nil
+}
+
+// createAIRuntime creates the KFServing AIRuntime shared by the IAFDemos in the namespace, if any model uses it.
+// Like the AutomationBase it carries no owner reference and is deleted by the finalizer of the last IAFDemo.
+// Models that name another runtime rely on it already existing.
+func (r *IAFDemoReconciler) createAIRuntime(recctx *reconcileContext, models []democartridgev1.ModelSpec) error {
+	usesDefault := false
+	for _, model := range models {
+		usesDefault = usesDefault || model.RuntimeOrDefault() == aiKFServingRuntime
+	}
+	if !usesDefault {
+		return nil
 	}
-	r.Log.Info("AIDeployment not found. Creating the custom resources")
 
 	//Check if Kubeflow secret exists
 	kfSecret := &corev1.Secret{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: aiModelKFSecret, Namespace: recctx.iafdemo.Namespace}, kfSecret)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: aiModelKFSecret, Namespace: recctx.iafdemo.Namespace}, kfSecret)
 	if err != nil && errors.IsNotFound(err) {
 		err = fmt.Errorf("Failed to find Secret %s in Namespace %s: %w", aiModelKFSecret, recctx.iafdemo.Namespace, err)
-		return false, err
+		return err
 	} else if err != nil {
-		return false, err
+		return err
 	}
-	r.Log.Info("Creating AIRuntime")
 	airuntime := &aiv1.AIRuntime{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      aiKFServingRuntime,
 			Namespace: recctx.iafdemo.Namespace,
 			Annotations: map[string]string{
-				"com.ibm.automation.cartridge": iafCartridgeInstanceName,
+				"com.ibm.automation.cartridge": recctx.names.cartridge,
 			},
 		},
 		Spec: aiv1.AIRuntimeSpec{
@@ -235,75 +320,129 @@ func (r *IAFDemoReconciler) deployModelOnKubeflow(recctx *reconcileContext) (boo
 		},
 	}
 
-	err = ctrl.SetControllerReference(recctx.iafdemo, airuntime, r.Scheme)
-	if err != nil {
-		return false, fmt.Errorf("Failed to set controller reference: %s", err)
+	err = r.Create(*recctx.ctx, airuntime)
+	if err == nil {
+		r.recordCreated(recctx, "AIRuntime", airuntime.Name)
+		return nil
+	} else if !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("Failed to create AIRuntime instance: %s", err)
 	}
 
-	err = r.Create(*recctx.ctx, airuntime)
-	if err != nil && !errors.IsAlreadyExists(err) {
-		return false, fmt.Errorf("Failed to create AIRuntime instance: %s", err)
+	existing := &aiv1.AIRuntime{}
+	if err = r.Get(*recctx.ctx, types.NamespacedName{Name: aiKFServingRuntime, Namespace: recctx.iafdemo.Namespace}, existing); err != nil {
+		return fmt.Errorf("Failed to get AIRuntime instance: %s", err)
 	}
+	return r.removeIAFDemoOwners(recctx, existing)
+}
 
-	r.Log.Info("Creating AIModel")
+// deployModelOnKubeflow creates or updates the AIModel and AIDeployment that serve a version of an entry of spec.models.
+// The AIModel describes the stored model and is shared with the other IAFDemos in the namespace that list it.
+func (r *IAFDemoReconciler) deployModelOnKubeflow(recctx *reconcileContext, model democartridgev1.ModelSpec, served servedVersion) error {
+	license := aiv1.License{Accept: bool(recctx.iafdemo.Spec.License.Accept)}
 	aimodel := &aiv1.AIModel{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      aiModelInstanceName,
-			Namespace: recctx.iafdemo.Namespace,
-			Annotations: map[string]string{
-				"com.ibm.automation.cartridge": iafCartridgeInstanceName,
-			},
-		},
-		Spec: aiv1.AIModelSpec{
-			Version: aiAPIVersion,
-			License: aiv1.License{
-				Accept: bool(recctx.iafdemo.Spec.License.Accept),
-			},
-			Description: aiModelInstanceDescription,
-			Type:        aiModelInstanceType,
-			Source:      aiv1.Credentials{SecretName: aiModelSourceSecret},
-			Store:       aiv1.Credentials{SecretName: aiModelStorageSecret},
-		},
+		ObjectMeta: metav1.ObjectMeta{Name: served.aimodel, Namespace: recctx.iafdemo.Namespace},
 	}
-
-	err = ctrl.SetControllerReference(recctx.iafdemo, aimodel, r.Scheme)
+	_, err := r.createOrPatchShared(recctx, aimodel, func() error {
+		aimodel.Labels = mergeStringMap(aimodel.Labels, map[string]string{aiModelSharedLabel: "true"})
+		// The cartridge of the IAFDemo that created the AIModel is kept, so the IAFDemos do not patch it in turn
+		if _, found := aimodel.Annotations["com.ibm.automation.cartridge"]; !found {
+			aimodel.Annotations = mergeStringMap(aimodel.Annotations, map[string]string{
+				"com.ibm.automation.cartridge": recctx.names.cartridge,
+			})
+		}
+		aimodel.Spec.Version = aiAPIVersion
+		aimodel.Spec.License = license
+		aimodel.Spec.Description = model.Description
+		aimodel.Spec.Type = model.TypeOrDefault()
+		aimodel.Spec.Source = aiv1.Credentials{SecretName: aiModelSourceSecret}
+		aimodel.Spec.Store = aiv1.Credentials{SecretName: recctx.iafdemo.Spec.ModelStore.S3.SecretNameOrDefault()}
+		return nil
+	})
 	if err != nil {
-		return false, fmt.Errorf("Failed to set controller reference: %s", err)
+		return err
 	}
 
-	err = r.Create(*recctx.ctx, aimodel)
-	if err != nil && !errors.IsAlreadyExists(err) {
-		return false, fmt.Errorf("Failed to create AIModel instance: %s", err)
+	aideployment := &aiv1.AIDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: served.aideployment, Namespace: recctx.iafdemo.Namespace},
 	}
+	_, err = r.createOrPatch(recctx, aideployment, func() error {
+		aideployment.Annotations = mergeStringMap(aideployment.Annotations, map[string]string{
+			"com.ibm.automation.cartridge": recctx.names.cartridge,
+		})
+		aideployment.Spec.Version = aiAPIVersion
+		aideployment.Spec.License = license
+		aideployment.Spec.Runtime = model.RuntimeOrDefault()
+		aideployment.Spec.Model = aiv1.Model{Name: aimodel.Name, Version: served.version}
+		return nil
+	})
+	return err
+}
 
-	r.Log.Info("Creating AIDeployment")
-	aideployment = &aiv1.AIDeployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      aiDeploymentInstanceName,
-			Namespace: recctx.iafdemo.Namespace,
-			Annotations: map[string]string{
-				"com.ibm.automation.cartridge": iafCartridgeInstanceName,
-			},
-		},
-		Spec: aiv1.AIDeploymentSpec{
-			Version: aiAPIVersion,
-			License: aiv1.License{
-				Accept: bool(recctx.iafdemo.Spec.License.Accept),
-			},
-			Runtime: aiKFServingRuntime,
-			Model:   aiv1.Model{Name: aiModelInstanceName, Version: aiModelInstanceVersion},
-		},
+// deleteRemovedAIModels deletes the AIDeployments of the IAFDemo whose entry was removed from spec.models, or whose
+// candidate version was promoted or rolled back, and the shared AIModels no IAFDemo in the namespace lists any more.
+// Leaving models nil deletes all the AIDeployments of the IAFDemo. The AIModels that earlier operator versions named
+// after the IAFDemo are deleted too.
+func (r *IAFDemoReconciler) deleteRemovedAIModels(recctx *reconcileContext, models []democartridgev1.ModelSpec) error {
+	wanted := map[string]bool{}
+	for _, model := range models {
+		for _, served := range servedVersions(recctx.names, model) {
+			wanted[served.aideployment] = true
+		}
 	}
-
-	err = ctrl.SetControllerReference(recctx.iafdemo, aideployment, r.Scheme)
+	referenced, err := r.referencedAIModels(recctx, models)
 	if err != nil {
-		return false, fmt.Errorf("Failed to set controller reference: %s", err)
+		return err
 	}
+	inNamespace := client.InNamespace(recctx.iafdemo.Namespace)
 
-	err = r.Create(*recctx.ctx, aideployment)
-	if err != nil && !errors.IsAlreadyExists(err) {
-		return false, fmt.Errorf("Failed to create AIDeployment instance: %s", err)
+	aideployments := &aiv1.AIDeploymentList{}
+	if err := r.List(*recctx.ctx, aideployments, inNamespace); err != nil {
+		return fmt.Errorf("Failed to list AIDeployments in Namespace %s: %w", recctx.iafdemo.Namespace, err)
+	}
+	for i := range aideployments.Items {
+		aideployment := &aideployments.Items[i]
+		if metav1.IsControlledBy(aideployment, recctx.iafdemo) && !wanted[aideployment.Name] {
+			if err := r.deleteIfExists(recctx, aideployment); err != nil {
+				return err
+			}
+		}
 	}
 
-	return true, nil
+	aimodels := &aiv1.AIModelList{}
+	if err := r.List(*recctx.ctx, aimodels, inNamespace); err != nil {
+		return fmt.Errorf("Failed to list AIModels in Namespace %s: %w", recctx.iafdemo.Namespace, err)
+	}
+	for i := range aimodels.Items {
+		aimodel := &aimodels.Items[i]
+		shared := aimodel.Labels[aiModelSharedLabel] == "true"
+		if (shared && !referenced[aimodel.Name]) || metav1.IsControlledBy(aimodel, recctx.iafdemo) {
+			if err := r.deleteIfExists(recctx, aimodel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteAIModelStore removes the models from the model store they were uploaded to.
+// The store is shared by every IAFDemo in the namespace, so it is kept while any other IAFDemo remains.
+func (r *IAFDemoReconciler) deleteAIModelStore(recctx *reconcileContext) error {
+	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
+
+	if other, err := r.otherIAFDemo(recctx); err != nil {
+		return err
+	} else if other != "" {
+		log.Info("Keeping the models since the model store is still used", "by", other)
+		return nil
+	}
+
+	store, err := r.newModelStore(recctx)
+	if err != nil && errors.IsNotFound(err) {
+		// Without the storage Secret nothing was ever uploaded
+		return nil
+	} else if err != nil {
+		return err
+	}
+	log.Info("Deleting the models from the model store", "type", recctx.iafdemo.Spec.ModelStore.TypeOrDefault())
+	return store.remove(*recctx.ctx, aiModelsDir)
 }
@@ -32,19 +32,28 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/prometheus/common/log"
 	basev1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/flink"
 	epv1alpha1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1alpha1"
 	epv1beta1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1beta1"
 	epcommon "github.ibm.com/automation-base-pak/abp-eventprocessing/pkg/commoncrd"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// flinkRESTPort is the port of the REST API the JobManager of the EventProcessor serves over TLS
+	flinkRESTPort = 8081
+	// flinkRequestTimeout bounds each call to the REST API of the JobManager
+	flinkRequestTimeout = 10 * time.Second
 )
 
 const elasticsearchDemoRawIndexJSON = `{
@@ -120,28 +129,18 @@ const elasticsearchDemoAnomalyIndexJSON = `{
 // In contrast, an EventProcessingTask is the unit of work that users submit to the EventProcessor
 // and both should be reconciled accordingly.
 func (r *IAFDemoReconciler) reconcileEventProcessor(recctx *reconcileContext) error {
-	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
 	namespace := recctx.iafdemo.Namespace
 	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
 
-	existingEventProcessingInstance := &epv1beta1.EventProcessor{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: eventProcessorName, Namespace: namespace}, existingEventProcessingInstance)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("EventProcessor instance not found. Creating...")
-
-		epInstance := newEventProcessingInstance(r.Cfg.EventProcessorImage, namespace, licenseAccept)
-
-		err = ctrl.SetControllerReference(recctx.iafdemo, epInstance, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
-		}
-
-		err = r.Create(*recctx.ctx, epInstance)
-		if err != nil {
-			return fmt.Errorf("Failed to create automationbase Event Processing instance: %s", err)
-		}
-	} else if err != nil {
-		return fmt.Errorf("Failed to get automationbase Event Processing instance: %s", err)
+	desired := newEventProcessingInstance(recctx.names, r.Cfg.EventProcessorImage, namespace, licenseAccept)
+	epInstance := &epv1beta1.EventProcessor{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: namespace}}
+	_, err := r.createOrPatch(recctx, epInstance, func() error {
+		epInstance.Annotations = mergeStringMap(epInstance.Annotations, desired.Annotations)
+		epInstance.Spec = desired.Spec
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to reconcile automationbase Event Processing instance: %w", err)
 	}
 	return nil
 }
@@ -154,17 +153,38 @@ func (r *IAFDemoReconciler) initializeElasticsearchIndices(recctx *reconcileCont
 		return err
 	}
 
-	// TODO: when the elastic connector is removed, the `-new` can be removed from these index names.
-	log.Info("Creating Elasticsearch index and mapping", "name", eventProcessorInputTopic)
-	if err = es.doRequest("PUT", eventProcessorInputTopic+"-new/", elasticsearchDemoRawIndexJSON); err != nil {
+	log.Info("Creating Elasticsearch index and mapping", "name", recctx.names.rawIndex)
+	if err = es.doRequest("PUT", recctx.names.rawIndex+"/", elasticsearchDemoRawIndexJSON); err != nil {
+		return err
+	}
+
+	log.Info("Creating Elasticsearch index and mapping", "name", recctx.names.riskIndex)
+	if err = es.doRequest("PUT", recctx.names.riskIndex+"/", elasticsearchDemoAnomalyIndexJSON); err != nil {
 		return err
 	}
 
-	log.Info("Creating Elasticsearch index and mapping", "name", eventProcessorRiskTopic)
-	if err = es.doRequest("PUT", eventProcessorRiskTopic+"-new/", elasticsearchDemoAnomalyIndexJSON); err != nil {
+	return nil
+}
+
+// deleteElasticsearchIndices removes the indices created by initializeElasticsearchIndices and written by the Flink job,
+// along with the indices with a -new suffix that earlier operator versions created but nothing wrote to
+func (r *IAFDemoReconciler) deleteElasticsearchIndices(recctx *reconcileContext) error {
+	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
+
+	es, err := r.getElasticsearchInfo(recctx)
+	if err != nil && errors.IsNotFound(err) {
+		// Without the CartridgeRequirements the indices were never created
+		return nil
+	} else if err != nil {
 		return err
 	}
 
+	for _, index := range []string{recctx.names.rawIndex, recctx.names.riskIndex, recctx.names.rawIndex + "-new", recctx.names.riskIndex + "-new"} {
+		log.Info("Deleting Elasticsearch index", "name", index)
+		if err = es.doRequest("DELETE", index, ""); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -228,19 +248,22 @@ func (es elasticsearchInfo) doRequest(method, path, body string) error {
 
 	resp, err := client.Do(req)
 	if err != nil {
+		countElasticsearchFailure(0)
 		return err
 	}
 	respBody, _ := ioutil.ReadAll(resp.Body) // Read to EOF so transport can be re-used
 	resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		// Unpack the response, and check if it only failed because it already existed.
+		// Unpack the response, and check if it only failed because it already existed, or was already deleted.
 		var esError elasticsearchError
 		if err = json.Unmarshal(respBody, &esError); err != nil {
+			countElasticsearchFailure(resp.StatusCode)
 			return err
 		}
-		if esError.Error.Type == "resource_already_exists_exception" {
+		if esError.Error.Type == "resource_already_exists_exception" || (method == "DELETE" && esError.Error.Type == "index_not_found_exception") {
 			return nil
 		}
+		countElasticsearchFailure(resp.StatusCode)
 		return fmt.Errorf("Elasticsearch request %v %v failed, status %v", method, path, resp.StatusCode)
 	}
 	return nil
@@ -252,16 +275,16 @@ func (r *IAFDemoReconciler) getElasticsearchInfo(recctx *reconcileContext) (elas
 
 	// Get the CartridgeRequirements to extract some information from its status
 	reqInst := &basev1beta1.CartridgeRequirements{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeReqInstanceName, Namespace: namespace}, reqInst)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridgeRequirements, Namespace: namespace}, reqInst)
 	if err != nil && errors.IsNotFound(err) {
-		err = fmt.Errorf("Failed to find CartridgeRequirements %s in Namespace %s: %w", iafCartridgeReqInstanceName, namespace, err)
+		err = fmt.Errorf("Failed to find CartridgeRequirements %s in Namespace %s: %w", recctx.names.cartridgeRequirements, namespace, err)
 		return es, err
 	} else if err != nil {
 		return es, err
 	}
 
 	if reqInst.Status.Components == nil || reqInst.Status.Components.ElasticSearch == nil {
-		return es, fmt.Errorf("Could not get ElasticSearch information from CartridgeRequirements %s in Namespace %s", iafCartridgeReqInstanceName, namespace)
+		return es, fmt.Errorf("Could not get ElasticSearch information from CartridgeRequirements %s in Namespace %s", recctx.names.cartridgeRequirements, namespace)
 	}
 
 	elasticsearchAuthSecretName := ""
@@ -279,7 +302,7 @@ func (r *IAFDemoReconciler) getElasticsearchInfo(recctx *reconcileContext) (elas
 		}
 	}
 	if len(es.endpoint) == 0 {
-		return es, fmt.Errorf("Could not get ElasticSearch Internal endpoint from CartridgeRequirements %s in Namespace %s", iafCartridgeReqInstanceName, namespace)
+		return es, fmt.Errorf("Could not get ElasticSearch Internal endpoint from CartridgeRequirements %s in Namespace %s", recctx.names.cartridgeRequirements, namespace)
 	}
 
 	elasticsearchAuthSecret := &corev1.Secret{}
@@ -331,34 +354,110 @@ func (r *IAFDemoReconciler) reconcileEventProcessingTask(recctx *reconcileContex
 	namespace := recctx.iafdemo.Namespace
 	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
 
-	existingEventProcessingTaskInstance := &epv1alpha1.EventProcessingTask{}
-
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: eventProcessingTaskInstanceName, Namespace: namespace}, existingEventProcessingTaskInstance)
+	var predictor modelPredictor
+	if aiEnabled, _ := r.aiEnabled(recctx); aiEnabled {
+		var err error
+		if predictor, err = r.getModelPredictor(recctx); err != nil {
+			// The job scores events with its built-in risk map until the predictor is Ready
+			log.Info("Model predictor is not available", "reason", err.Error())
+		}
+		log.Info("predictorEndPoint: "+predictor.url, "canary", predictor.canaryURL, "canaryPercent", predictor.canaryPercent)
+	}
+	// The job of the previous task is cancelled before it is replaced, so the status can report the endpoints
+	// of the job being submitted from the start
+	recctx.setModelPredictorStatus(predictor)
+	indexEvents, _ := r.elasticsearchEnabled(recctx)
+
+	desired := newEventProcessingTaskInstance(recctx.names, r.Cfg.EventProcessingTaskImage, namespace, licenseAccept, predictor, indexEvents, r.alertsTopic(recctx))
+
+	// The Flink job reads its program args only when it is submitted, so a task whose args changed, such as
+	// when the predictor endpoint appears or moves, is replaced to submit the job again
+	epTaskInstance := &epv1alpha1.EventProcessingTask{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: desired.Name, Namespace: namespace}, epTaskInstance)
+	if err == nil && !epTaskInstance.DeletionTimestamp.IsZero() {
+		// eventProcessingTaskReady waits for the replaced task to be gone
+		return nil
+	} else if err == nil && !equality.Semantic.DeepEqual(epTaskInstance.Spec.Args, desired.Spec.Args) {
+		log.Info("EventProcessingTask program args changed. Recreating...", "args", desired.Spec.Args)
+		if err = r.cancelFlinkJobs(recctx); err != nil {
+			return err
+		}
+		r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonRecreated, "Recreating EventProcessingTask %s to submit its job with new program args", desired.Name)
+		return r.deleteIfExists(recctx, epTaskInstance)
+	} else if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("Failed to get EventProcessingTask %s in Namespace %s: %w", desired.Name, namespace, err)
+	}
 
-	predictorEndPoint, _ := r.getPredictorURL(recctx)
-	log.Info("predictorEndPoint: " + predictorEndPoint)
+	epTaskInstance = &epv1alpha1.EventProcessingTask{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, epTaskInstance, func() error {
+		epTaskInstance.Annotations = mergeStringMap(epTaskInstance.Annotations, desired.Annotations)
+		epTaskInstance.Spec = desired.Spec
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to reconcile automationbase EventProcessingTask instance: %w", err)
+	}
+	return nil
+}
 
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("EventProcessingTask instance not found. Creating...")
+// cancelFlinkJobs cancels the job the EventProcessingTask submitted, which the job names after its consumer group.
+// Deleting the task leaves the job running on the Flink cluster of the EventProcessor, where it would keep
+// consuming the raw topic with the same group next to the job of the replacement task. Without a JobManager
+// Service there is no cluster and so no job to cancel.
+func (r *IAFDemoReconciler) cancelFlinkJobs(recctx *reconcileContext) error {
+	names := recctx.names
+	namespace := recctx.iafdemo.Namespace
+	service := &corev1.Service{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: names.flinkJobManager, Namespace: namespace}, service)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Failed to get Service %s in Namespace %s: %w", names.flinkJobManager, namespace, err)
+	}
 
-		epTaskInstance := newEventProcessingTaskInstance(r.Cfg.EventProcessingTaskImage, namespace, licenseAccept, predictorEndPoint)
+	transport, err := r.newTLSTransport(recctx, &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: names.flinkTLSSecret},
+		Key:                  "ca.crt",
+	})
+	if err != nil {
+		return err
+	}
+	client := &flink.Client{
+		HTTP: &http.Client{Transport: transport, Timeout: flinkRequestTimeout},
+		URL:  fmt.Sprintf("https://%s.%s.svc:%d", names.flinkJobManager, namespace, flinkRESTPort),
+	}
+	adminSecret := &corev1.Secret{}
+	err = r.Get(*recctx.ctx, types.NamespacedName{Name: names.flinkAdminSecret, Namespace: namespace}, adminSecret)
+	if err == nil {
+		client.Username = string(adminSecret.Data["username"])
+		client.Password = string(adminSecret.Data["password"])
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("Failed to get Secret %s in Namespace %s: %w", names.flinkAdminSecret, namespace, err)
+	}
 
-		err = ctrl.SetControllerReference(recctx.iafdemo, epTaskInstance, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
-		}
+	cancelled, err := client.CancelJobs(*recctx.ctx, names.flinkGroup)
+	if err != nil {
+		return fmt.Errorf("Failed to cancel Flink job %s of EventProcessor %s in Namespace %s: %w", names.flinkGroup, names.eventProcessor, namespace, err)
+	}
+	if len(cancelled) > 0 {
+		r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonJobCancelled, "Cancelled Flink job %s (%s)", names.flinkGroup, strings.Join(cancelled, ", "))
+	}
+	return nil
+}
 
-		err = r.Create(*recctx.ctx, epTaskInstance)
-		if err != nil {
-			return fmt.Errorf("Failed to create automationbase EventProcessingTask instance: %s", err)
-		}
+// eventProcessingTaskReady waits for an EventProcessingTask that was replaced to be deleted and created again
+func (r *IAFDemoReconciler) eventProcessingTaskReady(recctx *reconcileContext) (bool, error) {
+	epTaskInstance := &epv1alpha1.EventProcessingTask{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.eventProcessingTask, Namespace: recctx.iafdemo.Namespace}, epTaskInstance)
+	if errors.IsNotFound(err) {
+		return false, nil
 	} else if err != nil {
-		return fmt.Errorf("Failed to get automationbase EventProcessingTask instance: %s", err)
+		return false, err
 	}
-	return nil
+	return epTaskInstance.DeletionTimestamp.IsZero(), nil
 }
 
-func newEventProcessingInstance(eventProcessorImage, namespace string, licenseAccept bool) *epv1beta1.EventProcessor {
+func newEventProcessingInstance(names childNames, eventProcessorImage, namespace string, licenseAccept bool) *epv1beta1.EventProcessor {
 	saToUse := eventProcessorServiceAccountName
 	// Use the backtick here so we can have a formatted string with quotes etc
 	// this is useful so one can get the job logs from the JobManager pod and in the UI
@@ -412,10 +511,10 @@ func newEventProcessingInstance(eventProcessorImage, namespace string, licenseAc
 	}
 	return &epv1beta1.EventProcessor{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      eventProcessorName,
+			Name:      names.eventProcessor,
 			Namespace: namespace,
 			Annotations: map[string]string{
-				"com.ibm.automation.cartridge": iafCartridgeInstanceName,
+				"com.ibm.automation.cartridge": names.cartridge,
 			},
 		},
 		Spec: epv1beta1.EventProcessorSpec{
@@ -446,13 +545,27 @@ func newEventProcessingInstance(eventProcessorImage, namespace string, licenseAc
 	}
 }
 
-func newEventProcessingTaskInstance(image, namespace string, licenseAccept bool, predictorEndPoint string) *epv1alpha1.EventProcessingTask {
+func newEventProcessingTaskInstance(names childNames, image, namespace string, licenseAccept bool, predictor modelPredictor, indexEvents bool, alertsTopic string) *epv1alpha1.EventProcessingTask {
 	saToUse := eventProcessorServiceAccountName
-	programArgs := "?program-args=--groupId " + eventProcessorGroup + " --rawTopic " + eventProcessorInputTopic + " --riskTopic " + eventProcessorRiskTopic + " --esRawIndex " + eventProcessorInputTopic + " --esRiskIndex " + eventProcessorRiskTopic
+	programArgs := "?program-args=--groupId " + names.flinkGroup + " --rawTopic " + names.rawTopic + " --riskTopic " + names.riskTopic
+	programArgs += " --deadLetterTopic " + names.deadLetterTopic
+	if len(alertsTopic) > 0 {
+		programArgs += " --alertsTopic " + alertsTopic
+	}
+	if indexEvents {
+		programArgs += " --esRawIndex " + names.rawIndex + " --esRiskIndex " + names.riskIndex
+	} else {
+		// Without the flag the job requires the indices, so it never falls back to indices shared by every IAFDemo
+		programArgs += " --esDisabled"
+	}
 
-	if len(predictorEndPoint) > 0 {
+	if len(predictor.url) > 0 {
 		log.Info("Added model predictor URL to the job")
-		programArgs += " --modelPredictorURL " + predictorEndPoint
+		programArgs += " --modelPredictorURL " + predictor.url
+		if len(predictor.canaryURL) > 0 {
+			programArgs += " --modelCanaryPredictorURL " + predictor.canaryURL
+			programArgs += " --modelCanaryTrafficPercent " + strconv.Itoa(int(predictor.canaryPercent))
+		}
 	}
 	//programArgs += "\""
 
@@ -462,10 +575,10 @@ func newEventProcessingTaskInstance(image, namespace string, licenseAccept bool,
 
 	return &epv1alpha1.EventProcessingTask{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      eventProcessingTaskInstanceName,
+			Name:      names.eventProcessingTask,
 			Namespace: namespace,
 			Annotations: map[string]string{
-				"com.ibm.automation.cartridge": iafCartridgeInstanceName,
+				"com.ibm.automation.cartridge": names.cartridge,
 			},
 		},
 		Spec: epv1alpha1.EventProcessingTaskSpec{
@@ -473,7 +586,7 @@ func newEventProcessingTaskInstance(image, namespace string, licenseAccept bool,
 				Accept: licenseAccept,
 			},
 			Version:            "1.0.0",
-			ProcessorName:      eventProcessorName,
+			ProcessorName:      names.eventProcessor,
 			ServiceAccountName: &saToUse,
 			Image:              image,
 			RestartPolicy:      "OnFailure",
@@ -30,61 +30,81 @@ import (
 	"fmt"
 
 	kafkatopics "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1kafka"
-	"k8s.io/apimachinery/pkg/api/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	ctrl "sigs.k8s.io/controller-runtime"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
 )
 
-func (r *IAFDemoReconciler) createEventStream(recctx *reconcileContext, topicName string) (bool, error) {
+func (r *IAFDemoReconciler) reconcileEventStream(recctx *reconcileContext, topicName string, settings democartridgev1.KafkaTopicSettings) error {
 	namespace := recctx.iafdemo.Namespace
-	err := r.reconcileEventStream(recctx, topicName)
-	if err != nil {
-		return false, err
-	}
+	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
 
-	esInstanceName := topicName
-	existingEventStreamInstance := &kafkatopics.KafkaTopic{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: esInstanceName, Namespace: namespace}, existingEventStreamInstance)
+	desired := newEventStreamInstance(namespace, topicName, licenseAccept, settings)
+	esInstance := &kafkatopics.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: namespace}}
+	_, err := r.createOrPatch(recctx, esInstance, func() error {
+		esInstance.Labels = mergeStringMap(esInstance.Labels, desired.Labels)
+		esInstance.Spec.Partitions = desired.Spec.Partitions
+		esInstance.Spec.Replicas = desired.Spec.Replicas
+		esInstance.Spec.Config = desired.Spec.Config
+		return nil
+	})
+	return err
+}
+
+// kafkaTopicReady reports whether the topic operator has provisioned the current generation of a KafkaTopic.
+// A NotReady condition, for example for an invalid config, is returned as an error.
+func (r *IAFDemoReconciler) kafkaTopicReady(recctx *reconcileContext, topicName string) (bool, error) {
+	topic := &kafkatopics.KafkaTopic{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: topicName, Namespace: recctx.iafdemo.Namespace}, topic)
 	if err != nil {
 		return false, err
-	} else {
-		return false, nil
 	}
+	// The status is read generically, as the topic operator writes it in the Strimzi format
+	return strimziReady(statusOf(topic), topic.Generation)
 }
 
-func (r *IAFDemoReconciler) reconcileEventStream(recctx *reconcileContext, topicName string) error {
-	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
-	namespace := recctx.iafdemo.Namespace
-	licenseAccept := bool(recctx.iafdemo.Spec.License.Accept)
-	esInstanceName := topicName
-	existingEventStreamInstance := &kafkatopics.KafkaTopic{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: esInstanceName, Namespace: namespace}, existingEventStreamInstance)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("EventStream instance not found. Creating...")
-
-		esInstance := newEventStreamInstance(namespace, topicName, licenseAccept)
-
-		err = ctrl.SetControllerReference(recctx.iafdemo, esInstance, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
+// strimziReady reads the Ready and NotReady conditions of a resource managed by one of the Strimzi operators
+// of Event Streams, such as a KafkaTopic or a KafkaUser. A NotReady condition is returned as an error.
+func strimziReady(statusField interface{}, generation int64) (bool, error) {
+	status, ok := statusField.(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	if observed, found, _ := unstructured.NestedInt64(status, "observedGeneration"); found && observed < generation {
+		// The operator has not caught up with the last patch yet
+		return false, nil
+	}
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["status"] != string(corev1.ConditionTrue) {
+			continue
 		}
-
-		err = r.Create(*recctx.ctx, esInstance)
-		if err != nil {
-			return fmt.Errorf("Failed to create event stream instance: %s", err)
+		switch condition["type"] {
+		case "Ready":
+			return true, nil
+		case "NotReady":
+			return false, fmt.Errorf("%v: %v", condition["reason"], condition["message"])
 		}
-	} else if err != nil {
-		return fmt.Errorf("Failed to get event stream instance: %s", err)
 	}
-
-	return nil
+	return false, nil
 }
 
-func newEventStreamInstance(namespace string, topicName string, licenseAccept bool) *kafkatopics.KafkaTopic {
+func newEventStreamInstance(namespace string, topicName string, licenseAccept bool, settings democartridgev1.KafkaTopicSettings) *kafkatopics.KafkaTopic {
 	labels := map[string]string{
-		"ibmevents.ibm.com/cluster": "iaf-system",
+		"ibmevents.ibm.com/cluster": settings.ClusterOrDefault(),
+	}
+
+	config := map[string]intstr.IntOrString{
+		"retention.ms":  intstr.FromInt(604800000),
+		"segment.bytes": intstr.FromInt(1073741824),
+	}
+	for key, value := range settings.Config {
+		config[key] = value
 	}
 
 	return &kafkatopics.KafkaTopic{
@@ -98,12 +118,9 @@ func newEventStreamInstance(namespace string, topicName string, licenseAccept bo
 			Labels:    labels,
 		},
 		Spec: kafkatopics.KafkaTopicSpec{
-			Partitions: 1,
-			Replicas:   1,
-			Config: map[string]intstr.IntOrString{
-				"retention.ms":  intstr.FromInt(604800000),
-				"segment.bytes": intstr.FromInt(1073741824),
-			},
+			Partitions: settings.PartitionsOrDefault(),
+			Replicas:   settings.ReplicasOrDefault(),
+			Config:     config,
 		},
 	}
 }
@@ -0,0 +1,109 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// kafkaUserGVK is the KafkaUser of Event Streams. The base operator has no Go type for it, so it is handled as unstructured.
+var kafkaUserGVK = schema.GroupVersionKind{Group: "ibmevents.ibm.com", Version: "v1beta1", Kind: "KafkaUser"}
+
+func newKafkaUserObject(name, namespace string) *unstructured.Unstructured {
+	user := &unstructured.Unstructured{}
+	user.SetGroupVersionKind(kafkaUserGVK)
+	user.SetName(name)
+	user.SetNamespace(namespace)
+	return user
+}
+
+// reconcileKafkaUser provisions the SCRAM user the producer and server connect to Kafka as
+func (r *IAFDemoReconciler) reconcileKafkaUser(recctx *reconcileContext) error {
+	labels := map[string]string{
+		"ibmevents.ibm.com/cluster": recctx.iafdemo.Spec.Topics.Raw.ClusterOrDefault(),
+	}
+	spec := newKafkaUserSpec(recctx.names, r.alertsTopic(recctx))
+
+	user := newKafkaUserObject(recctx.names.kafkaUser, recctx.iafdemo.Namespace)
+	_, err := r.createOrPatch(recctx, user, func() error {
+		user.SetLabels(mergeStringMap(user.GetLabels(), labels))
+		return unstructured.SetNestedField(user.Object, spec, "spec")
+	})
+	return err
+}
+
+// kafkaUserReady reports whether the user operator has created the credentials of the KafkaUser
+func (r *IAFDemoReconciler) kafkaUserReady(recctx *reconcileContext) (bool, error) {
+	user := newKafkaUserObject(recctx.names.kafkaUser, recctx.iafdemo.Namespace)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: user.GetName(), Namespace: user.GetNamespace()}, user)
+	if err != nil {
+		return false, err
+	}
+	return strimziReady(user.Object["status"], user.GetGeneration())
+}
+
+// newKafkaUserSpec returns a SCRAM user whose ACLs are limited to the topics and consumer groups of one IAFDemo
+func newKafkaUserSpec(names childNames, alertsTopic string) map[string]interface{} {
+	topics := []string{names.rawTopic, names.riskTopic, names.deadLetterTopic}
+	if len(alertsTopic) > 0 {
+		topics = append(topics, alertsTopic)
+	}
+	groups := []string{names.flinkGroup, names.microservice(serverFunction)}
+
+	acls := []interface{}{}
+	for _, topic := range topics {
+		for _, operation := range []string{"Describe", "Read", "Write"} {
+			acls = append(acls, newKafkaACL("topic", topic, operation))
+		}
+	}
+	for _, group := range groups {
+		acls = append(acls, newKafkaACL("group", group, "Read"))
+	}
+
+	return map[string]interface{}{
+		"authentication": map[string]interface{}{
+			"type": "scram-sha-512",
+		},
+		"authorization": map[string]interface{}{
+			"type": "simple",
+			"acls": acls,
+		},
+	}
+}
+
+func newKafkaACL(resourceType, name, operation string) interface{} {
+	return map[string]interface{}{
+		"resource": map[string]interface{}{
+			"type":        resourceType,
+			"name":        name,
+			"patternType": "literal",
+		},
+		"operation": operation,
+		"host":      "*",
+	}
+}
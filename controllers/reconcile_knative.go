@@ -30,99 +30,377 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	knkafkachannel "knative.dev/eventing-contrib/kafka/channel/pkg/apis/messaging/v1alpha1"
 	knkafkabindings "knative.dev/eventing-contrib/kafka/source/pkg/apis/bindings/v1alpha1"
 	knkafkasource "knative.dev/eventing-contrib/kafka/source/pkg/apis/sources/v1alpha1"
+	kneventingapi "knative.dev/eventing/pkg/apis/eventing"
+	kneventing "knative.dev/eventing/pkg/apis/eventing/v1"
 	knmessaging "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 	knduckv1 "knative.dev/pkg/apis/duck/v1"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	basev1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1"
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
 )
 
 func (r *IAFDemoReconciler) reconcileKnative(recctx *reconcileContext, deployedName string) error {
-	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
 	namespace := recctx.iafdemo.Namespace
 
+	// Without the Knative CRDs nothing below can be created, so report that rather than the errors of each resource
+	if err := r.checkKnativeServed(r.knativeObjects(recctx, deployedName)); err != nil {
+		return err
+	}
+
 	// Get the CartridgeRequirements to extract some information from its status
 	cartridgeReqInstance := &basev1beta1.CartridgeRequirements{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeReqInstanceName, Namespace: namespace}, cartridgeReqInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridgeRequirements, Namespace: namespace}, cartridgeReqInstance)
 	if err != nil && errors.IsNotFound(err) {
-		return fmt.Errorf("Failed to find CartridgeRequirements %s in Namespace %s: %w", iafCartridgeReqInstanceName, namespace, err)
+		return fmt.Errorf("Failed to find CartridgeRequirements %s in Namespace %s: %w", recctx.names.cartridgeRequirements, namespace, err)
 	} else if err != nil {
 		return err
 	}
 
+	if cartridgeReqInstance.Status.Components == nil || cartridgeReqInstance.Status.Components.Kafka == nil {
+		return fmt.Errorf("Failed to get KafkaBootstrap servers from CartridgeRequirements %s in Namespace %s", recctx.names.cartridgeRequirements, namespace)
+	}
+
+	// Connect to the TLS listener as the KafkaUser of the IAFDemo, or to the plain listener without authentication
+	listener := recctx.iafdemo.Spec.Knative.ListenerOrDefault()
+	endpointName := "internal-service-plain"
+	if listener == democartridgev1.KafkaListenerTLS {
+		endpointName = "internal-service-tls"
+	}
 	kafkaBootstrapServers := ""
-	if cartridgeReqInstance.Status.Components != nil && cartridgeReqInstance.Status.Components.Kafka != nil {
-		endpoints := cartridgeReqInstance.Status.Components.Kafka.Endpoints
-		for _, endpoint := range endpoints {
-			if endpoint.Name == "internal-service-plain" {
-				kafkaBootstrapServers = endpoint.BootstrapServers
+	kafkaNet := knkafkabindings.KafkaNetSpec{}
+	for _, endpoint := range cartridgeReqInstance.Status.Components.Kafka.Endpoints {
+		if endpoint.Name != endpointName {
+			continue
+		}
+		kafkaBootstrapServers = endpoint.BootstrapServers
+		if listener == democartridgev1.KafkaListenerTLS {
+			kafkaNet = newKafkaSourceNet(recctx.names.kafkaSourceSecret)
+			if len(endpoint.CASecret.SecretName) > 0 {
+				kafkaNet.TLS.CACert = secretValue(endpoint.CASecret.SecretName, endpoint.CASecret.Key)
 			}
 		}
+		break
+	}
+	if len(kafkaBootstrapServers) == 0 {
+		return fmt.Errorf("Failed to find %s Kafka endpoint in CartridgeRequirements %s in Namespace %s", endpointName, recctx.names.cartridgeRequirements, namespace)
+	}
+	if listener == democartridgev1.KafkaListenerTLS {
+		err = r.reconcileKafkaSourceSecret(recctx, recctx.names.kafkaSourceSecret)
 	} else {
-		return fmt.Errorf("Failed to get KafkaBootstrap servers from CartridgeRequirements %s in Namespace %s", iafCartridgeReqInstanceName, namespace)
+		err = r.deleteIfExists(recctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: recctx.names.kafkaSourceSecret, Namespace: namespace}})
+	}
+	if err != nil {
+		return err
 	}
 
-	// Create KafkaSource if not present
-	kafkaSource := &knkafkasource.KafkaSource{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, kafkaSource)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("KafkaSource " + deployedName + " not found. Creating...")
-		kafkaSource = newKafkaSource(deployedName, namespace, kafkaBootstrapServers)
-		err = ctrl.SetControllerReference(recctx.iafdemo, kafkaSource, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
+	// Set up the channel or the Broker the KafkaSource sends the anomalies to, removing the other topology
+	settings := recctx.iafdemo.Spec.Knative
+	topics := []string{recctx.names.riskTopic}
+	var sink *knduckv1.KReference
+	if settings.DeliveryOrDefault() == democartridgev1.KnativeDeliveryBroker {
+		// The Broker also gets the alerts, so that a Trigger can pick the high-risk anomalies by their source topic.
+		// The source attribute is the only one that tells the two topics apart, so without a filter on it every
+		// high-risk anomaly would reach the server twice.
+		if alertsTopic := r.alertsTopic(recctx); len(alertsTopic) > 0 && len(settings.Filter["source"]) > 0 {
+			topics = append(topics, alertsTopic)
 		}
-		err = r.Create(*recctx.ctx, kafkaSource)
-		if err != nil {
-			return fmt.Errorf("Failed to create new KafkaSource %s in Namespace %s: %s", kafkaSource.Name, kafkaSource.Namespace, err)
+		if err = r.deleteKnativeChannel(recctx, deployedName); err != nil {
+			return err
 		}
-	} else if err != nil {
+		sink, err = r.reconcileKnativeBroker(recctx, deployedName, settings)
+	} else {
+		if err = r.deleteKnativeBroker(recctx, deployedName); err != nil {
+			return err
+		}
+		sink, err = r.reconcileKnativeChannel(recctx, deployedName, settings.ChannelKindOrDefault())
+	}
+	if err != nil {
 		return err
 	}
 
-	// Create InMemoryChannel if not present
-	inMemoryChannel := &knmessaging.InMemoryChannel{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, inMemoryChannel)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("InMemoryChannel " + deployedName + " not found. Creating...")
-		inMemoryChannel = newInMemoryChannel(deployedName, namespace)
-		err = ctrl.SetControllerReference(recctx.iafdemo, inMemoryChannel, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
+	// Create the KafkaSource, or bring it back in line
+	desiredSource := newKafkaSource(deployedName, namespace, kafkaBootstrapServers, kafkaNet, topics, sink)
+	kafkaSource := &knkafkasource.KafkaSource{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, kafkaSource, func() error {
+		kafkaSource.Spec.BootstrapServers = desiredSource.Spec.BootstrapServers
+		kafkaSource.Spec.Net = desiredSource.Spec.Net
+		kafkaSource.Spec.Topics = desiredSource.Spec.Topics
+		kafkaSource.Spec.ConsumerGroup = desiredSource.Spec.ConsumerGroup
+		kafkaSource.Spec.Sink = desiredSource.Spec.Sink
+		return nil
+	})
+	return err
+}
+
+// reconcileKnativeChannel sets up a channel of the given kind with a Subscription that delivers to the server.
+// It returns the channel for the KafkaSource to send to.
+func (r *IAFDemoReconciler) reconcileKnativeChannel(recctx *reconcileContext, deployedName string, kind democartridgev1.KnativeChannelKind) (*knduckv1.KReference, error) {
+	namespace := recctx.iafdemo.Namespace
+
+	// Create the channel if not present, and remove a channel of the other kind. Their specs are filled in by Knative.
+	var channel, otherChannel runtime.Object
+	inMemoryChannel := &knmessaging.InMemoryChannel{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	kafkaChannel := &knkafkachannel.KafkaChannel{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	if kind == democartridgev1.KnativeChannelKafka {
+		channel, otherChannel = kafkaChannel, inMemoryChannel
+	} else {
+		channel, otherChannel = inMemoryChannel, kafkaChannel
+	}
+	if err := r.deleteIfExists(recctx, otherChannel); err != nil {
+		return nil, err
+	}
+	_, err := r.createOrPatch(recctx, channel, func() error {
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Refer to the channel at the API version of its Go type, which checkKnativeServed found to be served
+	channelGVK, err := apiutil.GVKForObject(channel, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	// The channel of a Subscription cannot be changed, so a Subscription to another kind or version is replaced
+	desiredSubscription := newKnativeSubscription(deployedName, namespace, channelGVK.GroupVersion().String(), channelGVK.Kind, r.serverSubscriber(recctx, deployedName))
+	subscription := &knmessaging.Subscription{}
+	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, subscription)
+	if err == nil && (subscription.Spec.Channel.Kind != desiredSubscription.Spec.Channel.Kind ||
+		subscription.Spec.Channel.APIVersion != desiredSubscription.Spec.Channel.APIVersion) {
+		if err = r.deleteIfExists(recctx, subscription); err != nil {
+			return nil, err
+		}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// Create the Subscription, or bring it back in line
+	subscription = &knmessaging.Subscription{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, subscription, func() error {
+		subscription.Spec.Channel = desiredSubscription.Spec.Channel
+		subscription.Spec.Subscriber = desiredSubscription.Spec.Subscriber
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &knduckv1.KReference{
+		Kind:       channelGVK.Kind,
+		Namespace:  namespace,
+		Name:       deployedName,
+		APIVersion: channelGVK.GroupVersion().String(),
+	}, nil
+}
+
+// reconcileKnativeBroker sets up a Broker with a Trigger that delivers the anomalies that pass its filter to
+// the server. It returns the Broker for the KafkaSource to send to.
+func (r *IAFDemoReconciler) reconcileKnativeBroker(recctx *reconcileContext, deployedName string, settings democartridgev1.IAFDemoKnative) (*knduckv1.KReference, error) {
+	namespace := recctx.iafdemo.Namespace
+	brokerClass := settings.BrokerClassOrDefault()
+
+	// The class of a Broker cannot be changed, so a Broker of another class is replaced
+	broker := &kneventing.Broker{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, broker)
+	if err == nil && broker.Annotations[kneventingapi.BrokerClassKey] != brokerClass {
+		if err = r.deleteIfExists(recctx, broker); err != nil {
+			return nil, err
 		}
-		err = r.Create(*recctx.ctx, inMemoryChannel)
+	} else if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// Create the Broker if not present. Its spec is left to the defaults of the Broker class.
+	broker = &kneventing.Broker{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, broker, func() error {
+		broker.Annotations = mergeStringMap(broker.Annotations, map[string]string{kneventingapi.BrokerClassKey: brokerClass})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Trigger, or bring it back in line
+	desiredTrigger := newKnativeTrigger(deployedName, namespace, settings.Filter, r.serverSubscriber(recctx, deployedName))
+	trigger := &kneventing.Trigger{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, trigger, func() error {
+		trigger.Spec.Broker = desiredTrigger.Spec.Broker
+		trigger.Spec.Filter = desiredTrigger.Spec.Filter
+		trigger.Spec.Subscriber = desiredTrigger.Spec.Subscriber
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &knduckv1.KReference{
+		Kind:       "Broker",
+		Namespace:  namespace,
+		Name:       deployedName,
+		APIVersion: kneventing.SchemeGroupVersion.String(),
+	}, nil
+}
+
+// knativeObjects returns the Knative resources the IAFDemo needs with its delivery settings: the KafkaSource,
+// and either the channel and its Subscription or the Broker and its Trigger
+func (r *IAFDemoReconciler) knativeObjects(recctx *reconcileContext, deployedName string) []runtime.Object {
+	objectMeta := metav1.ObjectMeta{Name: deployedName, Namespace: recctx.iafdemo.Namespace}
+	objects := []runtime.Object{&knkafkasource.KafkaSource{ObjectMeta: objectMeta}}
+	settings := recctx.iafdemo.Spec.Knative
+	switch {
+	case settings.DeliveryOrDefault() == democartridgev1.KnativeDeliveryBroker:
+		return append(objects, &kneventing.Broker{ObjectMeta: objectMeta}, &kneventing.Trigger{ObjectMeta: objectMeta})
+	case settings.ChannelKindOrDefault() == democartridgev1.KnativeChannelKafka:
+		return append(objects, &knkafkachannel.KafkaChannel{ObjectMeta: objectMeta}, &knmessaging.Subscription{ObjectMeta: objectMeta})
+	default:
+		return append(objects, &knmessaging.InMemoryChannel{ObjectMeta: objectMeta}, &knmessaging.Subscription{ObjectMeta: objectMeta})
+	}
+}
+
+// checkKnativeServed returns an error naming the first of the objects whose kind the API server does not serve
+// at the version the operator uses. Without a RESTMapper nothing is checked.
+func (r *IAFDemoReconciler) checkKnativeServed(objects []runtime.Object) error {
+	if r.RESTMapper == nil {
+		return nil
+	}
+	for _, obj := range objects {
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
 		if err != nil {
-			return fmt.Errorf("Failed to create new InMemoryChannel %s in Namespace %s: %s", inMemoryChannel.Name, inMemoryChannel.Namespace, err)
+			return err
+		}
+		_, err = r.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("Knative is not installed: the API server does not serve %s %s", gvk.Kind, gvk.GroupVersion())
+		} else if err != nil {
+			return fmt.Errorf("Failed to look up the %s API: %w", gvk.Kind, err)
 		}
-	} else if err != nil {
-		return err
 	}
+	return nil
+}
 
-	// Create Subscription if not present
-	subscription := &knmessaging.Subscription{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, subscription)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("Subscription " + deployedName + " not found. Creating...")
-		subscription = newKnativeSubscription(deployedName, namespace)
-		err = ctrl.SetControllerReference(recctx.iafdemo, subscription, r.Scheme)
+// knativeReady reports whether every Knative resource of the IAFDemo has caught up with its spec and is Ready.
+// A resource whose Ready condition is False, such as a Subscription to a missing channel, is returned as an error.
+func (r *IAFDemoReconciler) knativeReady(recctx *reconcileContext) (bool, error) {
+	for _, obj := range r.knativeObjects(recctx, recctx.names.microservice(serverFunction)) {
+		key, _ := client.ObjectKeyFromObject(obj)
+		if err := r.Get(*recctx.ctx, key, obj); err != nil {
+			return false, err
+		}
+		objMeta, err := meta.Accessor(obj)
 		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
+			return false, err
 		}
-		err = r.Create(*recctx.ctx, subscription)
+		ready, err := knativeConditionReady(statusOf(obj), objMeta.GetGeneration())
 		if err != nil {
-			return fmt.Errorf("Failed to create new Subscription %s in Namespace %s: %s", subscription.Name, subscription.Namespace, err)
+			gvk, _ := apiutil.GVKForObject(obj, r.Scheme)
+			return false, fmt.Errorf("%s %s: %w", gvk.Kind, key.Name, err)
+		}
+		if !ready {
+			return false, nil
 		}
-	} else if err != nil {
-		return err
 	}
-	return nil
+	return true, nil
+}
+
+// knativeConditionReady reads the Ready condition of a Knative resource. Knative sets it to Unknown while the
+// resource is being reconciled and to False when it cannot become Ready, which is returned as an error.
+func knativeConditionReady(statusField interface{}, generation int64) (bool, error) {
+	status, ok := statusField.(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	if observed, _, _ := unstructured.NestedInt64(status, "observedGeneration"); observed < generation {
+		// The Knative controller has not caught up with the last patch yet
+		return false, nil
+	}
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		switch condition["status"] {
+		case string(corev1.ConditionTrue):
+			return true, nil
+		case string(corev1.ConditionFalse):
+			return false, fmt.Errorf("%v: %v", condition["reason"], condition["message"])
+		}
+	}
+	return false, nil
+}
+
+// reconcileKafkaSourceSecret keeps the SCRAM credentials of the KafkaUser in the form the KafkaSource reads them.
+// The KafkaSource takes the user name and SASL type from a Secret too, so the password Secret generated by the
+// user operator cannot be used as it is.
+func (r *IAFDemoReconciler) reconcileKafkaSourceSecret(recctx *reconcileContext, secretName string) error {
+	namespace := recctx.iafdemo.Namespace
+	userSecret := &corev1.Secret{}
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.kafkaUser, Namespace: namespace}, userSecret)
+	if err != nil {
+		return fmt.Errorf("Failed to get the Secret of KafkaUser %s in Namespace %s: %w", recctx.names.kafkaUser, namespace, err)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, secret, func() error {
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{
+			"user":     []byte(recctx.names.kafkaUser),
+			"password": userSecret.Data["password"],
+			"saslType": []byte("SCRAM-SHA-512"),
+		}
+		return nil
+	})
+	return err
+}
+
+// newKafkaSourceNet returns the TLS and SASL settings of a KafkaSource that authenticates with the credentials in secretName
+func newKafkaSourceNet(secretName string) knkafkabindings.KafkaNetSpec {
+	return knkafkabindings.KafkaNetSpec{
+		SASL: knkafkabindings.KafkaSASLSpec{
+			Enable:   true,
+			User:     secretValue(secretName, "user"),
+			Password: secretValue(secretName, "password"),
+			Type:     secretValue(secretName, "saslType"),
+		},
+		TLS: knkafkabindings.KafkaTLSSpec{
+			Enable: true,
+		},
+	}
+}
+
+func secretValue(secretName, key string) knkafkabindings.SecretValueFromSource {
+	return knkafkabindings.SecretValueFromSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+			Key:                  key,
+		},
+	}
+}
+
+func (r *IAFDemoReconciler) deleteKnativeChannel(recctx *reconcileContext, deployedName string) error {
+	namespace := recctx.iafdemo.Namespace
+	return r.deleteAll(recctx,
+		&knmessaging.Subscription{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}},
+		&knmessaging.InMemoryChannel{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}},
+		&knkafkachannel.KafkaChannel{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}})
+}
+
+func (r *IAFDemoReconciler) deleteKnativeBroker(recctx *reconcileContext, deployedName string) error {
+	namespace := recctx.iafdemo.Namespace
+	return r.deleteAll(recctx,
+		&kneventing.Trigger{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}},
+		&kneventing.Broker{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}})
 }
 
-func newKafkaSource(deployedName, namespace, kafkaBootstrapServers string) *knkafkasource.KafkaSource {
+func newKafkaSource(deployedName, namespace, kafkaBootstrapServers string, kafkaNet knkafkabindings.KafkaNetSpec, topics []string, sink *knduckv1.KReference) *knkafkasource.KafkaSource {
 	return &knkafkasource.KafkaSource{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        deployedName,
@@ -132,33 +410,18 @@ func newKafkaSource(deployedName, namespace, kafkaBootstrapServers string) *knka
 		Spec: knkafkasource.KafkaSourceSpec{
 			KafkaAuthSpec: knkafkabindings.KafkaAuthSpec{
 				BootstrapServers: []string{kafkaBootstrapServers},
+				Net:              kafkaNet,
 			},
-			Topics:        []string{eventProcessorRiskTopic},
+			Topics:        topics,
 			ConsumerGroup: deployedName,
 			Sink: &knduckv1.Destination{
-				Ref: &knduckv1.KReference{
-					Kind:       "InMemoryChannel",
-					Namespace:  namespace,
-					Name:       deployedName,
-					APIVersion: "messaging.knative.dev/v1alpha1",
-				},
+				Ref: sink,
 			},
 		},
 	}
 }
 
-func newInMemoryChannel(deployedName, namespace string) *knmessaging.InMemoryChannel {
-	return &knmessaging.InMemoryChannel{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        deployedName,
-			Namespace:   namespace,
-			Annotations: map[string]string{},
-		},
-		Spec: knmessaging.InMemoryChannelSpec{},
-	}
-}
-
-func newKnativeSubscription(deployedName, namespace string) *knmessaging.Subscription {
+func newKnativeSubscription(deployedName, namespace, channelAPIVersion, channelKind string, subscriber *knduckv1.KReference) *knmessaging.Subscription {
 	return &knmessaging.Subscription{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        deployedName,
@@ -167,19 +430,33 @@ func newKnativeSubscription(deployedName, namespace string) *knmessaging.Subscri
 		},
 		Spec: knmessaging.SubscriptionSpec{
 			Channel: corev1.ObjectReference{
-				Kind:       "InMemoryChannel",
+				Kind:       channelKind,
 				Namespace:  namespace,
 				Name:       deployedName,
-				APIVersion: "messaging.knative.dev/v1alpha1",
+				APIVersion: channelAPIVersion,
 			},
 			Subscriber: &knduckv1.Destination{
-				Ref: &knduckv1.KReference{
-					Kind:       "Service",
-					Namespace:  namespace,
-					Name:       deployedName,
-					APIVersion: "v1",
-				},
+				Ref: subscriber,
 			},
 		},
 	}
 }
+
+func newKnativeTrigger(deployedName, namespace string, filter map[string]string, subscriber *knduckv1.KReference) *kneventing.Trigger {
+	trigger := &kneventing.Trigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployedName,
+			Namespace: namespace,
+		},
+		Spec: kneventing.TriggerSpec{
+			Broker: deployedName,
+			Subscriber: knduckv1.Destination{
+				Ref: subscriber,
+			},
+		},
+	}
+	if len(filter) > 0 {
+		trigger.Spec.Filter = &kneventing.TriggerFilter{Attributes: kneventing.TriggerFilterAttributes(filter)}
+	}
+	return trigger
+}
@@ -0,0 +1,139 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	knduckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/common"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/server"
+)
+
+// knativeServiceGVK is the Service of Knative Serving. The operator does not depend on the Serving module,
+// so the Service is handled as unstructured.
+var knativeServiceGVK = schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}
+
+func newKnativeServiceObject(name, namespace string) *unstructured.Unstructured {
+	service := &unstructured.Unstructured{}
+	service.SetGroupVersionKind(knativeServiceGVK)
+	service.SetName(name)
+	service.SetNamespace(namespace)
+	return service
+}
+
+// reconcileKnativeService runs the server as a Knative Service, which scales to zero while no anomalies arrive.
+// Only the fields the operator manages are set, so the defaults Knative fills in do not cause a new revision.
+func (r *IAFDemoReconciler) reconcileKnativeService(recctx *reconcileContext, service *unstructured.Unstructured, envVars []corev1.EnvVar) error {
+	if err := r.checkKnativeServed([]runtime.Object{service}); err != nil {
+		return err
+	}
+	labels := common.LabelsFor(recctx.iafdemo.Name, serverFunction)
+	desired := corev1.Container{
+		Image:           r.Cfg.ServerImage,
+		Name:            serverFunction,
+		Env:             envVars,
+		ImagePullPolicy: corev1.PullAlways,
+		Ports:           []corev1.ContainerPort{{ContainerPort: server.Port}},
+	}
+
+	_, err := r.createOrPatch(recctx, service, func() error {
+		template, _, _ := unstructured.NestedMap(service.Object, "spec", "template")
+		if template == nil {
+			template = map[string]interface{}{}
+		}
+		for key, value := range labels {
+			if err := unstructured.SetNestedField(template, value, "metadata", "labels", key); err != nil {
+				return err
+			}
+		}
+		if err := unstructured.SetNestedField(template, "0", "metadata", "annotations", "autoscaling.knative.dev/minScale"); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(template, "iaf-demo-cartridge-operator", "spec", "serviceAccountName"); err != nil {
+			return err
+		}
+
+		// Update the server container the same way as in a Deployment
+		podSpec := &corev1.PodSpec{}
+		containers, _, _ := unstructured.NestedSlice(template, "spec", "containers")
+		for _, c := range containers {
+			content, _ := c.(map[string]interface{})
+			container := corev1.Container{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &container); err != nil {
+				return err
+			}
+			podSpec.Containers = append(podSpec.Containers, container)
+		}
+		setContainer(podSpec, desired)
+		containers = make([]interface{}, 0, len(podSpec.Containers))
+		for i := range podSpec.Containers {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec.Containers[i])
+			if err != nil {
+				return err
+			}
+			containers = append(containers, content)
+		}
+		if err := unstructured.SetNestedSlice(template, containers, "spec", "containers"); err != nil {
+			return err
+		}
+		return unstructured.SetNestedMap(service.Object, template, "spec", "template")
+	})
+	return err
+}
+
+// serverReady reports whether the Knative Service of the server is Ready. A server run as a Deployment is
+// Ready once applied.
+func (r *IAFDemoReconciler) serverReady(recctx *reconcileContext) (bool, error) {
+	if !r.serverKnativeService(recctx) {
+		return true, nil
+	}
+	service := newKnativeServiceObject(recctx.names.microservice(serverFunction), recctx.iafdemo.Namespace)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: service.GetName(), Namespace: service.GetNamespace()}, service)
+	if err != nil {
+		return false, err
+	}
+	return knativeConditionReady(service.Object["status"], service.GetGeneration())
+}
+
+// serverSubscriber returns the reference the Subscription or Trigger delivers the anomalies to: the Knative
+// Service of the server, or the Kubernetes Service in front of its Deployment
+func (r *IAFDemoReconciler) serverSubscriber(recctx *reconcileContext, deployedName string) *knduckv1.KReference {
+	subscriber := &knduckv1.KReference{
+		Kind:       "Service",
+		Namespace:  recctx.iafdemo.Namespace,
+		Name:       deployedName,
+		APIVersion: "v1",
+	}
+	if r.serverKnativeService(recctx) {
+		subscriber.APIVersion = knativeServiceGVK.GroupVersion().String()
+	}
+	return subscriber
+}
@@ -27,7 +27,6 @@ package controllers
 
 import (
 	"fmt"
-	"strings"
 
 	routev1 "github.com/openshift/api/route/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -36,14 +35,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	ctrl "sigs.k8s.io/controller-runtime"
 
 	basev1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1"
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/common"
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/server"
 )
 
-func (r *IAFDemoReconciler) reconcileMicroservice(recctx *reconcileContext, deployedName string) error {
+func (r *IAFDemoReconciler) reconcileMicroservice(recctx *reconcileContext, shortName string) error {
+	deployedName := recctx.names.microservice(shortName)
 	namespace := recctx.iafdemo.Namespace
 	messagesPerGroup := recctx.iafdemo.Spec.MessagesPerGroup
 	secondsToPause := recctx.iafdemo.Spec.SecondsToPause
@@ -53,22 +52,25 @@ func (r *IAFDemoReconciler) reconcileMicroservice(recctx *reconcileContext, depl
 
 	// Get the CartridgeRequirements to extract some information from its status
 	cartridgeReqInstance := &basev1beta1.CartridgeRequirements{}
-	err := r.Get(*recctx.ctx, types.NamespacedName{Name: iafCartridgeReqInstanceName, Namespace: namespace}, cartridgeReqInstance)
+	err := r.Get(*recctx.ctx, types.NamespacedName{Name: recctx.names.cartridgeRequirements, Namespace: namespace}, cartridgeReqInstance)
 	if err != nil && errors.IsNotFound(err) {
-		return fmt.Errorf("Failed to find CartridgeRequirements %s in Namespace %s: %w", iafCartridgeReqInstanceName, namespace, err)
+		return fmt.Errorf("Failed to find CartridgeRequirements %s in Namespace %s: %w", recctx.names.cartridgeRequirements, namespace, err)
 	} else if err != nil {
 		return err
 	}
 
-	shortName := strings.Replace(deployedName, "demo", "", 1) // strip of demo leaving "server" or "producer"
-	log.Info("shortName is " + shortName)
-
 	envVars := []corev1.EnvVar{{
 		Name:  "FUNCTION",
 		Value: shortName,
 	}, {
 		Name:  "KAFKA_TOPIC",
-		Value: eventProcessorInputTopic,
+		Value: recctx.names.rawTopic,
+	}, {
+		Name:  "DEAD_LETTER_TOPIC",
+		Value: recctx.names.deadLetterTopic,
+	}, {
+		Name:  "ALERTS_TOPIC",
+		Value: r.alertsTopic(recctx),
 	}, {
 		Name:  "MESSAGES_PER_GROUP",
 		Value: messagesPerGroup,
@@ -76,13 +78,13 @@ func (r *IAFDemoReconciler) reconcileMicroservice(recctx *reconcileContext, depl
 		Name:  "SECONDS_TO_PAUSE",
 		Value: secondsToPause,
 	}, {
-		Name:  "SAMPLE_REPITITIONS",
+		Name:  "SEQUENCE_REPITITIONS",
 		Value: sequenceRepititions,
 	}}
 
 	internalTLSEndpointFound := false
 	if cartridgeReqInstance.Status.Components == nil || cartridgeReqInstance.Status.Components.Kafka == nil {
-		return fmt.Errorf("Failed to get KafkaBootstrap servers from CartridgeRequirements %s in Namespace %s", iafCartridgeReqInstanceName, namespace)
+		return fmt.Errorf("Failed to get KafkaBootstrap servers from CartridgeRequirements %s in Namespace %s", recctx.names.cartridgeRequirements, namespace)
 	}
 	for _, endpoint := range cartridgeReqInstance.Status.Components.Kafka.Endpoints {
 		if endpoint.Name == "internal-service-tls" {
@@ -106,145 +108,110 @@ func (r *IAFDemoReconciler) reconcileMicroservice(recctx *reconcileContext, depl
 				})
 			}
 
-			if len(endpoint.Authentication.Secret.SecretName) > 0 {
-				log.Info("Kafka Authentication secret found in CartridgeRequirements")
-				envVars = append(envVars, corev1.EnvVar{
-					Name:  "KAFKA_USERNAME",
-					Value: endpoint.Authentication.Secret.SecretName,
-				}, corev1.EnvVar{
-					Name: "KAFKA_PASSWORD",
-					ValueFrom: &corev1.EnvVarSource{
-						SecretKeyRef: &corev1.SecretKeySelector{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: endpoint.Authentication.Secret.SecretName,
-							},
-							Key: "password",
+			// Authenticate as the KafkaUser of the IAFDemo, whose ACLs only cover its own topics and groups.
+			// The user operator generates its password in a Secret named after the user.
+			envVars = append(envVars, corev1.EnvVar{
+				Name:  "KAFKA_USERNAME",
+				Value: recctx.names.kafkaUser,
+			}, corev1.EnvVar{
+				Name: "KAFKA_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: recctx.names.kafkaUser,
 						},
+						Key: "password",
 					},
-				})
-			}
+				},
+			})
 
 			internalTLSEndpointFound = true
 			break
 		}
 	}
 	if !internalTLSEndpointFound {
-		return fmt.Errorf("Failed to find internal-service-tls Kafka endpoint in CartridgeRequirements %s in Namespace %s", iafCartridgeReqInstanceName, namespace)
+		return fmt.Errorf("Failed to find internal-service-tls Kafka endpoint in CartridgeRequirements %s in Namespace %s", recctx.names.cartridgeRequirements, namespace)
 	}
 
-	// Create deployment if not present
-	mDeployment := &appsv1.Deployment{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, mDeployment)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("Microservice " + deployedName + " deployment not found. Creating...")
-		replicas := int32(1)
-		mDeployment = &appsv1.Deployment{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        deployedName,
-				Namespace:   namespace,
-				Annotations: map[string]string{},
-			},
-			Spec: appsv1.DeploymentSpec{
-				Replicas: &replicas,
-				Selector: &metav1.LabelSelector{
-					MatchLabels: common.Labels,
-				},
-				Template: corev1.PodTemplateSpec{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: common.Labels,
-					},
-					Spec: corev1.PodSpec{
-						ServiceAccountName: "iaf-demo-cartridge-operator",
-						Containers: []corev1.Container{{
-							Image:           r.Cfg.ServerImage,
-							Name:            shortName,
-							Env:             envVars,
-							ImagePullPolicy: corev1.PullAlways,
-						}},
-					},
-				},
-			},
+	// The server runs either as a Knative Service or as a Deployment, so the resources of the other kind are removed
+	if shortName == serverFunction {
+		knativeService := newKnativeServiceObject(deployedName, namespace)
+		if r.serverKnativeService(recctx) {
+			if err = r.deleteMicroserviceDeployment(recctx, shortName); err != nil {
+				return err
+			}
+			return r.reconcileKnativeService(recctx, knativeService, envVars)
 		}
-		err = ctrl.SetControllerReference(recctx.iafdemo, mDeployment, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
+		if err = r.deleteIfExists(recctx, knativeService); err != nil {
+			return err
 		}
+	}
+
+	labels := common.LabelsFor(recctx.iafdemo.Name, shortName)
 
-		err = r.Create(*recctx.ctx, mDeployment)
-		if err != nil {
-			return fmt.Errorf("Failed to create new Deployment %s in Namespace %s: %s", mDeployment.Name, mDeployment.Namespace, err)
+	// Create the deployment, or bring it back in line with the IAFDemo spec and operator config
+	replicas := int32(1)
+	mDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, mDeployment, func() error {
+		mDeployment.Spec.Replicas = &replicas
+		// The selector cannot be changed once the deployment exists
+		if mDeployment.Spec.Selector == nil {
+			mDeployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
 		}
-	} else if err != nil {
+		mDeployment.Spec.Template.Labels = labels
+		mDeployment.Spec.Template.Spec.ServiceAccountName = "iaf-demo-cartridge-operator"
+		setContainer(&mDeployment.Spec.Template.Spec, corev1.Container{
+			Image:           r.Cfg.ServerImage,
+			Name:            shortName,
+			Env:             envVars,
+			ImagePullPolicy: corev1.PullAlways,
+		})
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	// Create service if not present
-	mService := &corev1.Service{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: namespace}, mService)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("Microservice " + deployedName + " service not found. Creating...")
-		mService = &corev1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        deployedName,
-				Namespace:   recctx.iafdemo.Namespace,
-				Annotations: map[string]string{},
-			},
-			Spec: corev1.ServiceSpec{
-				Ports: []corev1.ServicePort{{
-					Name:       "http",
-					Port:       80,
-					TargetPort: intstr.FromInt(server.Port),
-					Protocol:   "TCP",
-				}},
-				Selector: common.Labels,
-				Type:     corev1.ServiceTypeClusterIP,
-			},
-		}
-		err = ctrl.SetControllerReference(recctx.iafdemo, mService, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
-		}
-
-		err = r.Create(*recctx.ctx, mService)
-		if err != nil {
-			return fmt.Errorf("Failed to create new Service %s in Namespace %s: %s", mService.Name, mService.Namespace, err)
-		}
-	} else if err != nil {
+	// Create the service, or bring it back in line
+	mService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, mService, func() error {
+		mService.Spec.Ports = []corev1.ServicePort{{
+			Name:       "http",
+			Port:       80,
+			TargetPort: intstr.FromInt(server.Port),
+			Protocol:   "TCP",
+		}}
+		mService.Spec.Selector = labels
+		mService.Spec.Type = corev1.ServiceTypeClusterIP
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	// Create Route if not present
-	mRoute := &routev1.Route{}
-	err = r.Get(*recctx.ctx, types.NamespacedName{Name: deployedName, Namespace: recctx.iafdemo.Namespace}, mRoute)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("Microservice " + deployedName + " route not found. Creating...")
-		mRoute = &routev1.Route{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        deployedName,
-				Namespace:   recctx.iafdemo.Namespace,
-				Annotations: map[string]string{},
-			},
-			Spec: routev1.RouteSpec{
-				Port: &routev1.RoutePort{
-					TargetPort: intstr.FromInt(server.Port),
-				},
-				To: routev1.RouteTargetReference{
-					Kind: "Service",
-					Name: deployedName,
-				},
-			},
-		}
-		err = ctrl.SetControllerReference(recctx.iafdemo, mRoute, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("Failed to set controller reference: %s", err)
+	// Create the route, or bring it back in line. The host is left to OpenShift.
+	mRoute := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: deployedName, Namespace: namespace}}
+	_, err = r.createOrPatch(recctx, mRoute, func() error {
+		mRoute.Spec.Port = &routev1.RoutePort{
+			TargetPort: intstr.FromInt(server.Port),
 		}
+		mRoute.Spec.To.Kind = "Service"
+		mRoute.Spec.To.Name = deployedName
+		return nil
+	})
+	return err
+}
 
-		err = r.Create(*recctx.ctx, mRoute)
-		if err != nil {
-			return fmt.Errorf("Failed to create new Route %s in Namespace %s: %s", mRoute.Name, mRoute.Namespace, err)
+// setContainer updates the container in spec with the same name as desired, adding it if there is none.
+// Only the fields the operator manages are copied, so server defaults on the container are kept.
+func setContainer(spec *corev1.PodSpec, desired corev1.Container) {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == desired.Name {
+			spec.Containers[i].Image = desired.Image
+			spec.Containers[i].Env = desired.Env
+			spec.Containers[i].ImagePullPolicy = desired.ImagePullPolicy
+			return
 		}
-	} else if err != nil {
-		return err
 	}
-	return nil
+	spec.Containers = append(spec.Containers, desired)
 }
@@ -0,0 +1,105 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/inference"
+)
+
+const (
+	// modelSampleFile is the sample data of the producer, which is shipped in the operator image
+	modelSampleFile = "sample.csv"
+	// modelSampleInvoices is the number of sample invoices sent to each predictor
+	modelSampleInvoices = 5
+	// modelVerifyTimeout bounds the wait for a predictor to score the sample invoices
+	modelVerifyTimeout = 10 * time.Second
+)
+
+// verifyModels sends sample invoices to the predictor of every served model version and checks that each
+// answers with a score per invoice. The predictors are only checked again when their endpoints or the
+// IAFDemo spec change, so that the latency in the report does not rewrite the status on every reconcile.
+func (r *IAFDemoReconciler) verifyModels(recctx *reconcileContext) error {
+	log := r.Log.WithValues("iafdemo", recctx.req.NamespacedName)
+
+	var endpoints []string
+	for _, model := range recctx.iafdemo.Status.Models {
+		for _, version := range model.Versions {
+			if version.Endpoint == "" {
+				return fmt.Errorf("AIDeployment %s has no predictor endpoint", version.Deployment)
+			}
+			endpoints = append(endpoints, version.Endpoint)
+		}
+	}
+	if previous := meta.FindStatusCondition(recctx.iafdemo.Status.Conditions, democartridgev1.ConditionModelVerified); previous != nil &&
+		previous.Status == metav1.ConditionTrue && previous.ObservedGeneration == recctx.iafdemo.Generation && reportsAll(previous.Message, endpoints) {
+		recctx.modelVerification = previous.Message
+		return nil
+	}
+
+	invoices, err := inference.SampleInvoices(modelSampleFile, modelSampleInvoices)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: modelVerifyTimeout}
+	var reports []string
+	for _, model := range recctx.iafdemo.Status.Models {
+		for _, version := range model.Versions {
+			ctx, cancel := context.WithTimeout(*recctx.ctx, modelVerifyTimeout)
+			result, err := inference.Score(ctx, client, version.Endpoint, invoices)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("Model %s %s at %s failed to score %d sample invoices: %w", model.Name, version.Version, version.Endpoint, len(invoices), err)
+			}
+			log.Info("Model answered the sample invoices", "model", model.Name, "version", version.Version, "latency", result.Latency.String(), "scores", result.Scores)
+			risks := result.Risks()
+			reports = append(reports, fmt.Sprintf("Model %s %s at %s scored %d sample invoices in %s over the %s protocol: %d Low, %d Medium, %d High",
+				model.Name, version.Version, version.Endpoint, len(invoices), result.Latency.Round(time.Millisecond), result.Protocol,
+				risks["Low"], risks["Medium"], risks["High"]))
+		}
+	}
+	recctx.modelVerification = strings.Join(reports, "; ")
+	return nil
+}
+
+// reportsAll reports whether an earlier verification report covers every endpoint
+func reportsAll(report string, endpoints []string) bool {
+	for _, endpoint := range endpoints {
+		if !strings.Contains(report, " at "+endpoint+" ") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,200 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// stages returns the pipeline that deploys the demo, in the order the stages run.
+// A new stage needs a condition type in the API and an entry here.
+func (r *IAFDemoReconciler) stages() []stage {
+	return []stage{{
+		name:     democartridgev1.ConditionCartridge,
+		describe: func(recctx *reconcileContext) string { return "Cartridge " + recctx.names.cartridge },
+		apply:    r.applyCartridge,
+		ready:    r.cartridgeReady,
+	}, {
+		name:      democartridgev1.ConditionAutomationBase,
+		dependsOn: []string{democartridgev1.ConditionCartridge},
+		describe:  func(recctx *reconcileContext) string { return "AutomationBase " + automationBaseInstanceName },
+		apply:     r.createAutomationBaseInstance,
+		ready:     r.automationBaseReady,
+	}, {
+		name:      democartridgev1.ConditionCartridgeRequirements,
+		dependsOn: []string{democartridgev1.ConditionAutomationBase},
+		describe: func(recctx *reconcileContext) string {
+			return "CartridgeRequirements " + recctx.names.cartridgeRequirements
+		},
+		apply: r.createCartridgeRequirementsInstance,
+		ready: r.cartridgeRequirementsReady,
+	}, {
+		// AI scoring is optional: the Flink job falls back to its built-in risk map without it
+		name:      democartridgev1.ConditionAIModels,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  describeAIDeployments,
+		enabled:   r.aiEnabled,
+		apply:     r.setupAIModels,
+		ready:     r.aiModelsReady,
+		cleanup:   r.deleteAIResources,
+		onError:   continueOnError,
+	}, {
+		// The smoke test only reports whether the models answer; events are scored either way
+		name:      democartridgev1.ConditionModelVerified,
+		dependsOn: []string{democartridgev1.ConditionAIModels},
+		describe:  func(recctx *reconcileContext) string { return "Model predictors" },
+		enabled:   r.aiEnabled,
+		apply:     r.verifyModels,
+		report:    func(recctx *reconcileContext) string { return recctx.modelVerification },
+		onError:   continueOnError,
+	}, {
+		name:      democartridgev1.ConditionRawKafkaTopic,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "KafkaTopic " + recctx.names.rawTopic },
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileEventStream(recctx, recctx.names.rawTopic, recctx.iafdemo.Spec.Topics.Raw)
+		},
+		ready: func(recctx *reconcileContext) (bool, error) {
+			return r.kafkaTopicReady(recctx, recctx.names.rawTopic)
+		},
+	}, {
+		name:      democartridgev1.ConditionAnomalyKafkaTopic,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "KafkaTopic " + recctx.names.riskTopic },
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileEventStream(recctx, recctx.names.riskTopic, recctx.iafdemo.Spec.Topics.Anomaly)
+		},
+		ready: func(recctx *reconcileContext) (bool, error) {
+			return r.kafkaTopicReady(recctx, recctx.names.riskTopic)
+		},
+	}, {
+		name:      democartridgev1.ConditionDeadLetterKafkaTopic,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "KafkaTopic " + recctx.names.deadLetterTopic },
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileEventStream(recctx, recctx.names.deadLetterTopic, recctx.iafdemo.Spec.Topics.DeadLetter)
+		},
+		ready: func(recctx *reconcileContext) (bool, error) {
+			return r.kafkaTopicReady(recctx, recctx.names.deadLetterTopic)
+		},
+	}, {
+		name:      democartridgev1.ConditionAlertsKafkaTopic,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "KafkaTopic " + recctx.names.alertsTopic },
+		enabled:   r.alertsEnabled,
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileEventStream(recctx, recctx.names.alertsTopic, recctx.iafdemo.Spec.Topics.Alerts)
+		},
+		ready: func(recctx *reconcileContext) (bool, error) {
+			return r.kafkaTopicReady(recctx, recctx.names.alertsTopic)
+		},
+		cleanup: func(recctx *reconcileContext) error {
+			return r.deleteKafkaTopic(recctx, recctx.names.alertsTopic)
+		},
+	}, {
+		name:      democartridgev1.ConditionKafkaUser,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "KafkaUser " + recctx.names.kafkaUser },
+		apply:     r.reconcileKafkaUser,
+		ready:     r.kafkaUserReady,
+	}, {
+		name:      democartridgev1.ConditionEventProcessor,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "EventProcessor " + recctx.names.eventProcessor },
+		apply:     r.reconcileEventProcessor,
+	}, {
+		name:      democartridgev1.ConditionElasticsearchIndices,
+		dependsOn: []string{democartridgev1.ConditionCartridgeRequirements},
+		describe:  func(recctx *reconcileContext) string { return "Elasticsearch indices" },
+		enabled:   r.elasticsearchEnabled,
+		apply:     r.initializeElasticsearchIndices,
+		cleanup:   r.cleanupElasticsearchIndices,
+	}, {
+		name: democartridgev1.ConditionEventProcessingTask,
+		dependsOn: []string{
+			democartridgev1.ConditionRawKafkaTopic,
+			democartridgev1.ConditionAnomalyKafkaTopic,
+			democartridgev1.ConditionDeadLetterKafkaTopic,
+			democartridgev1.ConditionAlertsKafkaTopic,
+			democartridgev1.ConditionEventProcessor,
+			democartridgev1.ConditionElasticsearchIndices,
+		},
+		after: []string{democartridgev1.ConditionAIModels},
+		describe: func(recctx *reconcileContext) string {
+			return "EventProcessingTask " + recctx.names.eventProcessingTask
+		},
+		apply: r.reconcileEventProcessingTask,
+		ready: r.eventProcessingTaskReady,
+	}, {
+		name: democartridgev1.ConditionProducerMicroservice,
+		dependsOn: []string{
+			democartridgev1.ConditionRawKafkaTopic,
+			democartridgev1.ConditionDeadLetterKafkaTopic,
+			democartridgev1.ConditionKafkaUser,
+		},
+		describe: func(recctx *reconcileContext) string {
+			return "Microservice " + recctx.names.microservice(producerFunction)
+		},
+		enabled: r.producerEnabled,
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileMicroservice(recctx, producerFunction)
+		},
+		cleanup: func(recctx *reconcileContext) error {
+			return r.deleteMicroservice(recctx, producerFunction)
+		},
+	}, {
+		name:      democartridgev1.ConditionServerMicroservice,
+		dependsOn: []string{democartridgev1.ConditionKafkaUser},
+		describe: func(recctx *reconcileContext) string {
+			return "Microservice " + recctx.names.microservice(serverFunction)
+		},
+		enabled: r.serverEnabled,
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileMicroservice(recctx, serverFunction)
+		},
+		ready: r.serverReady,
+		cleanup: func(recctx *reconcileContext) error {
+			return r.deleteMicroservice(recctx, serverFunction)
+		},
+	}, {
+		// Knative only delivers the anomalies to the server, so the demo carries on without it. It runs after the
+		// server, which is the sink of its subscription or trigger.
+		name: democartridgev1.ConditionKnative,
+		dependsOn: []string{
+			democartridgev1.ConditionAnomalyKafkaTopic,
+			democartridgev1.ConditionKafkaUser,
+			democartridgev1.ConditionServerMicroservice,
+		},
+		describe: func(recctx *reconcileContext) string { return "Knative resources" },
+		enabled:  r.knativeEnabled,
+		apply: func(recctx *reconcileContext) error {
+			return r.reconcileKnative(recctx, recctx.names.microservice(serverFunction))
+		},
+		ready:   r.knativeReady,
+		cleanup: r.deleteKnative,
+		onError: continueOnError,
+	}}
+}
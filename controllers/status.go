@@ -0,0 +1,155 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+// initStageConditions adds an Unknown condition for every stage that has not reported yet,
+// so that the status always lists the full pipeline.
+func (recctx *reconcileContext) initStageConditions() {
+	for _, conditionType := range democartridgev1.StageConditionTypes {
+		if meta.FindStatusCondition(recctx.iafdemo.Status.Conditions, conditionType) == nil {
+			recctx.setStageCondition(conditionType, metav1.ConditionUnknown, democartridgev1.ReasonPending, "Stage has not been reconciled yet")
+		}
+	}
+	if recctx.iafdemo.Status.Phase == "" {
+		recctx.iafdemo.Status.Phase = democartridgev1.PhasePending
+	}
+}
+
+func (recctx *reconcileContext) setStageCondition(conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&recctx.iafdemo.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: recctx.iafdemo.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func (recctx *reconcileContext) stageReady(conditionType, message string) {
+	recctx.setStageCondition(conditionType, metav1.ConditionTrue, democartridgev1.ReasonReady, message)
+}
+
+func (recctx *reconcileContext) stageWaiting(conditionType, message string) {
+	recctx.setStageCondition(conditionType, metav1.ConditionFalse, democartridgev1.ReasonWaiting, message)
+}
+
+func (recctx *reconcileContext) stageFailed(conditionType string, err error) {
+	recctx.setStageCondition(conditionType, metav1.ConditionFalse, democartridgev1.ReasonFailed, err.Error())
+}
+
+func (recctx *reconcileContext) stageDisabled(conditionType, message string) {
+	recctx.setStageCondition(conditionType, metav1.ConditionFalse, democartridgev1.ReasonDisabled, message)
+}
+
+func (recctx *reconcileContext) stageSkipped(conditionType, message string) {
+	recctx.setStageCondition(conditionType, metav1.ConditionFalse, democartridgev1.ReasonSkipped, message)
+}
+
+// setModelStatus records the status of an AI model, replacing any earlier status of the model with the same name
+func (recctx *reconcileContext) setModelStatus(model democartridgev1.ModelStatus) {
+	models := recctx.iafdemo.Status.Models
+	for i := range models {
+		if models[i].Name == model.Name {
+			models[i] = model
+			return
+		}
+	}
+	recctx.iafdemo.Status.Models = append(models, model)
+}
+
+// setModelEndpoint records the predictor endpoint of the AIDeployment that serves a version of an AI model
+func (recctx *reconcileContext) setModelEndpoint(deployment, endpoint string) {
+	for i := range recctx.iafdemo.Status.Models {
+		versions := recctx.iafdemo.Status.Models[i].Versions
+		for j := range versions {
+			if versions[j].Deployment == deployment {
+				versions[j].Endpoint = endpoint
+			}
+		}
+	}
+}
+
+// setModelPredictorStatus records the model predictor the EventProcessingTask was submitted with
+func (recctx *reconcileContext) setModelPredictorStatus(predictor modelPredictor) {
+	if predictor.url == "" {
+		recctx.iafdemo.Status.ModelPredictor = nil
+		return
+	}
+	recctx.iafdemo.Status.ModelPredictor = &democartridgev1.ModelPredictorStatus{
+		Endpoint:             predictor.url,
+		CanaryEndpoint:       predictor.canaryURL,
+		CanaryTrafficPercent: predictor.canaryPercent,
+	}
+}
+
+// stageFailed reports whether any stage of the pipeline has a Failed condition
+func stageFailed(conditions []metav1.Condition) bool {
+	for _, condition := range conditions {
+		if condition.Status == metav1.ConditionFalse && condition.Reason == democartridgev1.ReasonFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// updateStatus derives the phase from the outcome of the reconcile stages and writes the status subresource.
+func (r *IAFDemoReconciler) updateStatus(recctx *reconcileContext, result ctrl.Result, reconcileErr error) error {
+	status := &recctx.iafdemo.Status
+	previousPhase := status.Phase
+	switch {
+	case reconcileErr != nil:
+		status.Phase = democartridgev1.PhaseFailed
+	case result.Requeue:
+		status.Phase = democartridgev1.PhaseInstalling
+	// A RequeueAfter alone rechecks stages whose failure is tolerated, which leaves the demo running but Degraded
+	case stageFailed(status.Conditions):
+		status.Phase = democartridgev1.PhaseDegraded
+	default:
+		status.Phase = democartridgev1.PhaseReady
+	}
+	status.ObservedGeneration = recctx.iafdemo.Generation
+
+	if status.Phase == democartridgev1.PhaseReady && previousPhase != democartridgev1.PhaseReady {
+		r.recordEvent(recctx, corev1.EventTypeNormal, eventReasonCompleted, "All stages of the demo are Ready")
+	}
+
+	err := r.Status().Update(*recctx.ctx, recctx.iafdemo)
+	if err != nil && errors.IsNotFound(err) {
+		// The IAFDemo was deleted while it was being reconciled
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+)
+
+const (
+	// retryBaseDelay is the first requeue delay while waiting on a dependency; it doubles on every retry
+	retryBaseDelay = 2 * time.Second
+	// retryMaxDelay caps the requeue delay, in case a watch event is missed
+	retryMaxDelay = 2 * time.Minute
+	// recheckDelay is how long a stage that failed with continueOnError waits before it is retried,
+	// as the watches miss what it waits for, such as Knative being installed
+	recheckDelay = 5 * time.Minute
+)
+
+// statusOrSpecChangedPredicate passes on updates to a watched child that changed its spec or its status,
+// such as a dependency turning Ready. Updates that only touch metadata, like resourceVersion bumps, are dropped.
+var statusOrSpecChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld == nil || e.MetaNew == nil {
+			return true
+		}
+		if e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() {
+			return true
+		}
+		return !equality.Semantic.DeepEqual(statusOf(e.ObjectOld), statusOf(e.ObjectNew))
+	},
+}
+
+// statusOf returns the status of any kind of object, or nil if it has none
+func statusOf(obj runtime.Object) interface{} {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	return content["status"]
+}
+
+// requestsForNamespace maps an event on a shared resource to a reconcile of every IAFDemo in its namespace
+func (r *IAFDemoReconciler) requestsForNamespace(obj handler.MapObject) []ctrl.Request {
+	demos := &democartridgev1.IAFDemoList{}
+	if err := r.List(context.Background(), demos, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Failed to list IAFDemos", "namespace", obj.Meta.GetNamespace())
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(demos.Items))
+	for _, demo := range demos.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: demo.Name, Namespace: demo.Namespace}})
+	}
+	return requests
+}
+
+// requestsForKafkaUserSecret maps an event on the Secret the user operator keeps the password of a KafkaUser in
+// to a reconcile of the IAFDemo the KafkaUser belongs to, so that a rotated password reaches the copy the
+// KafkaSource authenticates with
+func (r *IAFDemoReconciler) requestsForKafkaUserSecret(obj handler.MapObject) []ctrl.Request {
+	demos := &democartridgev1.IAFDemoList{}
+	if err := r.List(context.Background(), demos, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Failed to list IAFDemos", "namespace", obj.Meta.GetNamespace())
+		return nil
+	}
+	for _, demo := range demos.Items {
+		if newChildNames(demo.Name).kafkaUser == obj.Meta.GetName() {
+			return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: demo.Name, Namespace: demo.Namespace}}}
+		}
+	}
+	return nil
+}
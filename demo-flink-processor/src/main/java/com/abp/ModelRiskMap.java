@@ -9,6 +9,8 @@
  ********************************************************** {COPYRIGHT-END} ***/
 package com.abp;
 
+import java.util.concurrent.ThreadLocalRandom;
+
 import org.apache.flink.api.common.functions.MapFunction;
 
 import com.abp.rest.client.ModelInferResponse;
@@ -30,14 +32,29 @@ public class ModelRiskMap implements MapFunction<Invoice, Invoice> {
     public static final String DEFAULT_AI_MODEL_NAME = "anomaly-classifier-predictor";
 
     private String predictorUrl = null;
+    private String canaryPredictorUrl = null;
+    private int canaryTrafficPercent = 0;
 
     public ModelRiskMap(String predictorUrl) {
         this.predictorUrl = predictorUrl;
     }
 
+    /**
+     * Scores canaryTrafficPercent of the invoices with the candidate model
+     * version served at canaryPredictorUrl, and the rest with predictorUrl
+     */
+    public ModelRiskMap(String predictorUrl, String canaryPredictorUrl, int canaryTrafficPercent) {
+        this.predictorUrl = predictorUrl;
+        this.canaryPredictorUrl = canaryPredictorUrl;
+        this.canaryTrafficPercent = canaryTrafficPercent;
+    }
+
     @Override
     public Invoice map(Invoice inv) throws Exception {
-        ModelRestClient modelRestClient = new ModelRestClient(predictorUrl);
+        String url = predictorUrl;
+        if (canaryPredictorUrl != null && ThreadLocalRandom.current().nextInt(100) < canaryTrafficPercent)
+            url = canaryPredictorUrl;
+        ModelRestClient modelRestClient = new ModelRestClient(url);
         ModelInferResponse response = modelRestClient.callRestInferenceService(inv.Pay_Delay);
         int out = response.getOutput();
         inv.Risk = RISK_LOW;
@@ -59,8 +59,6 @@ public class Processor {
   private static final String DEFAULT_RAW_TOPIC = "iafdemo-raw";
   private static final String DEFAULT_RISK_TOPIC = "iafdemo-anomaly";
   private static final String DEFAULT_ELASTIC_HOST = "https://iaf-system-elasticsearch-es:9200";
-  private static final String DEFAULT_ELASTIC_RAW_INDEX = "iafdemo-raw";
-  private static final String DEFAULT_ELASTIC_RISK_INDEX = "iafdemo-anomaly";
 
   private static final String ELASTIC_USERNAME = "elasticsearch-admin";
   private static final String ELASTIC_PASSWORD = "***";
@@ -92,12 +90,26 @@ public class Processor {
       LOGGER.info("Using default risk topic name as none provided through args");
     }
 
+    // Optional topics: invalid invoices go to the dead-letter topic, high-risk anomalies also go to the alerts topic
+    final String deadLetterTopic = parameter.get("deadLetterTopic", "");
+    final String alertsTopic = parameter.get("alertsTopic", "");
+
     final String esHost = System.getenv().getOrDefault("ELASTIC_URI", DEFAULT_ELASTIC_HOST);
+    // Indexing is switched off with --esDisabled; otherwise the operator names the indices of the IAFDemo
+    final boolean esDisabled = parameter.has("esDisabled");
+    final String esRawIndex = parameter.get("esRawIndex", "");
+    final String esRiskIndex = parameter.get("esRiskIndex", "");
+    if (!esDisabled && (esRawIndex.equals("") || esRiskIndex.equals(""))) {
+      throw new IllegalArgumentException("--esRawIndex and --esRiskIndex are required unless --esDisabled is set");
+    }
     final String bootstrapServers = System.getenv().getOrDefault("KAFKA_BOOTSTRAP_SERVERS", DEFAULT_KAFKA_BOOTSTRAP_SERVERS);
     String predictorUrl = parameter.get("modelPredictorURL");
     if (predictorUrl == null || predictorUrl.equals("")) {
       LOGGER.warn("No model predictor URL found");
     }
+    // Optional candidate model version, which scores a share of the invoices during a canary rollout
+    final String canaryPredictorUrl = parameter.get("modelCanaryPredictorURL", "");
+    final int canaryTrafficPercent = parameter.getInt("modelCanaryTrafficPercent", 0);
 
     properties.setProperty("bootstrap.servers", bootstrapServers);
     properties.setProperty("group.id", groupId);
@@ -106,8 +118,16 @@ public class Processor {
     LOGGER.info("Kafka group ID: " + groupId);
     LOGGER.info("Raw topic name: " + rawTopic);
     LOGGER.info("Risk topic name: " + riskTopic);
-    LOGGER.info("Elastic host name: " + esHost);
+    LOGGER.info("Dead-letter topic name: " + deadLetterTopic);
+    LOGGER.info("Alerts topic name: " + alertsTopic);
+    if (esDisabled) {
+      LOGGER.info("Elasticsearch indexing is disabled");
+    } else {
+      LOGGER.info("Elastic host name: " + esHost);
+      LOGGER.info("Elastic indices: " + esRawIndex + ", " + esRiskIndex);
+    }
     LOGGER.info("Predictor URL: " + predictorUrl);
+    LOGGER.info("Canary predictor URL: " + canaryPredictorUrl + " (" + canaryTrafficPercent + "%)");
 
     // Create execution environment
     StreamExecutionEnvironment env = StreamExecutionEnvironment.getExecutionEnvironment();
@@ -155,18 +175,11 @@ public class Processor {
     List<HttpHost> esHttphost = new ArrayList<>();
     esHttphost.add(HttpHost.create(esHost));
 
-    // use a ElasticsearchSink.Builder to create an ElasticsearchSink
     // use a ElasticsearchSink.Builder to create an ElasticsearchSink
     ElasticsearchSink.Builder<RawInput> esSinkBuilder = new ElasticsearchSink.Builder<>(
       esHttphost,
       new ElasticsearchSinkFunction<RawInput>() {
         private IndexRequest createIndexRequest(RawInput element) {
-        // Use here due to the fact local variables referenced from an inner class must be final or effectively final
-        String esRawIndex = parameter.get("esRawIndex");
-        if (esRawIndex == null || esRawIndex.equals("")) {
-          esRawIndex = DEFAULT_ELASTIC_RAW_INDEX;
-          LOGGER.info("Using default elastic raw index as none provided through args");
-        }
           return Requests.indexRequest()
               .index(esRawIndex)
               .source(element.rawInputToJson());
@@ -192,14 +205,24 @@ public class Processor {
         restClientBuilder.setHttpClientConfigCallback(Processor::setElasticCredentials);
     });
 
-    rawInputStream.addSink(esSinkBuilder.build());
+    if (!esDisabled) {
+      rawInputStream.addSink(esSinkBuilder.build());
+    }
 
 		// Read valid invoices
     // Setup source for Invoice events
  		FlinkKafkaConsumer<Invoice> kafkaConsumerInvoice = new FlinkKafkaConsumer<>(rawTopic, new InvoiceSchema(), properties);
  		kafkaConsumerInvoice.setStartFromEarliest();
 
-		DataStream<Invoice> invoiceStream = env.addSource(kafkaConsumerInvoice).filter(new ValidFilter());
+		DataStream<Invoice> allInvoiceStream = env.addSource(kafkaConsumerInvoice);
+		final ValidFilter validFilter = new ValidFilter();
+		DataStream<Invoice> invoiceStream = allInvoiceStream.filter(validFilter);
+
+		// Keep the invalid invoices for inspection rather than dropping them
+		if (!deadLetterTopic.equals("")) {
+			FlinkKafkaProducer<Invoice> deadLetterProducer = new FlinkKafkaProducer<>(deadLetterTopic, new InvoiceSchema(), properties);
+			allInvoiceStream.filter(invoice -> !validFilter.filter(invoice)).addSink(deadLetterProducer);
+		}
 
 		// transform late invoices to risks
     DataStream<Invoice> riskStream;
@@ -207,6 +230,8 @@ public class Processor {
     // then use the existing map
     if(predictorUrl == null || predictorUrl.equals(""))
         riskStream = invoiceStream.filter(new LateFilter()).map(new RiskMap());
+    else if (!canaryPredictorUrl.equals(""))
+        riskStream = invoiceStream.filter(new LateFilter()).map(new ModelRiskMap(predictorUrl, canaryPredictorUrl, canaryTrafficPercent));
     else
         riskStream = invoiceStream.filter(new LateFilter()).map(new ModelRiskMap(predictorUrl));
 
@@ -214,16 +239,17 @@ public class Processor {
 		FlinkKafkaProducer<Invoice> riskProducer = new FlinkKafkaProducer<>(riskTopic, new InvoiceSchema(), properties);
 		riskStream.addSink(riskProducer);
 
+		// Setup target for high-risk alerts
+		if (!alertsTopic.equals("")) {
+			FlinkKafkaProducer<Invoice> alertsProducer = new FlinkKafkaProducer<>(alertsTopic, new InvoiceSchema(), properties);
+			riskStream.filter(invoice -> RiskMap.RISK_HIGH.equals(invoice.Risk)).addSink(alertsProducer);
+		}
+
     // use a ElasticsearchSink.Builder to create an ElasticsearchSink
     ElasticsearchSink.Builder<Invoice> esSinkBuilderForAnomaly = new ElasticsearchSink.Builder<>(
       esHttphost,
       new ElasticsearchSinkFunction<Invoice>() {
         private IndexRequest createIndexRequest(Invoice invoice) {
-        String esRiskIndex = parameter.get("esRiskIndex");
-        if (esRiskIndex == null || esRiskIndex.equals("")) {
-          esRiskIndex = DEFAULT_ELASTIC_RISK_INDEX;
-          LOGGER.info("Using default elastic risk index as none provided through args");
-        }
           return Requests.indexRequest()
               .index(esRiskIndex)
               .source(invoice.toJson());
@@ -249,9 +275,12 @@ public class Processor {
         restClientBuilder.setHttpClientConfigCallback(Processor::setElasticCredentials);
     });
     
-    riskStream.addSink(esSinkBuilderForAnomaly.build());
+    if (!esDisabled) {
+      riskStream.addSink(esSinkBuilderForAnomaly.build());
+    }
 
-    env.execute();
+    // The job is named after its consumer group, so that the operator can find and cancel it
+    env.execute(groupId);
 	}
 
 
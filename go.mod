@@ -15,6 +15,7 @@ require (
 	github.com/onsi/ginkgo v1.14.2
 	github.com/onsi/gomega v1.10.4
 	github.com/openshift/api v3.9.1-0.20190924102528-32369d4db2ad+incompatible
+	github.com/prometheus/client_golang v1.9.0
 	github.com/prometheus/common v0.15.0
 	github.com/xdg/scram v0.0.0-20180814205039-7eeb5667e42c
 	github.ibm.com/automation-base-pak/abp-ai-operator v0.0.0-20210225134141-11775c1d8ffc
@@ -46,7 +46,7 @@ func main() {
 	case "operator":
 		operator.Start(cfg)
 	case "server":
-		server.Start()
+		server.Start(cfg)
 	case "producer":
 		producer.Start(cfg)
 	default:
@@ -13,3 +13,15 @@ package common
 var Labels = map[string]string{
 	"app": "automationbasepak",
 }
+
+// LabelsFor returns the common labels plus labels that select one component of one IAFDemo instance
+func LabelsFor(instance, component string) map[string]string {
+	labels := map[string]string{
+		"app.kubernetes.io/instance":  instance,
+		"app.kubernetes.io/component": component,
+	}
+	for key, value := range Labels {
+		labels[key] = value
+	}
+	return labels
+}
@@ -21,10 +21,13 @@ type Config struct {
 	KafkaUsername            string `env:"KAFKA_USERNAME"`
 	KafkaPassword            string `env:"KAFKA_PASSWORD"`
 	KafkaTopic               string `env:"KAFKA_TOPIC"`
+	DeadLetterTopic          string `env:"DEAD_LETTER_TOPIC"`
+	AlertsTopic              string `env:"ALERTS_TOPIC"`
 	ServerImage              string `env:"SERVER_IMAGE"`
 	EventProcessorImage      string `env:"EVENT_PROCESSOR_IMAGE"`
 	EventProcessingTaskImage string `env:"EVENT_PROCESSING_TASK_IMAGE"`
 	UseKnative               string `env:"USE_KNATIVE"`
+	EnableWebhooks           string `env:"ENABLE_WEBHOOKS"`
 	MessagesPerGroup         string `env:"MESSAGES_PER_GROUP"`
 	SecondsToPause           string `env:"SECONDS_TO_PAUSE"`
 	SequenceRepititions      string `env:"SEQUENCE_REPITITIONS"`
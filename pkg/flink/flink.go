@@ -0,0 +1,115 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+
+// Package flink talks to the REST API of a Flink JobManager, to cancel the jobs an EventProcessingTask submitted
+// before the task is replaced.
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Job is a job of the Flink cluster as listed by /jobs/overview
+type Job struct {
+	ID    string `json:"jid"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// Active reports whether the job still runs or is about to, so that cancelling it stops it consuming events
+func (j Job) Active() bool {
+	switch j.State {
+	case "FINISHED", "FAILED", "CANCELED", "CANCELLING", "SUSPENDED":
+		return false
+	default:
+		return true
+	}
+}
+
+// Client calls the REST API of a JobManager
+type Client struct {
+	HTTP *http.Client
+	// URL is the base URL of the REST API, such as https://<cluster>-jobmanager:8081
+	URL string
+	// Username and Password are sent with basic authentication, if set
+	Username string
+	Password string
+}
+
+type jobsOverview struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// Jobs lists the jobs of the cluster
+func (c *Client) Jobs(ctx context.Context) ([]Job, error) {
+	content, err := c.do(ctx, http.MethodGet, "/jobs/overview", http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	overview := jobsOverview{}
+	if err = json.Unmarshal(content, &overview); err != nil {
+		return nil, fmt.Errorf("Malformed Flink jobs overview: %w", err)
+	}
+	return overview.Jobs, nil
+}
+
+// Cancel asks the cluster to cancel a job
+func (c *Client) Cancel(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodPatch, "/jobs/"+id+"?mode=cancel", http.StatusAccepted)
+	return err
+}
+
+// CancelJobs cancels the active jobs with the given name and returns their IDs
+func (c *Client) CancelJobs(ctx context.Context, name string) ([]string, error) {
+	jobs, err := c.Jobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cancelled []string
+	for _, job := range jobs {
+		if job.Name != name || !job.Active() {
+			continue
+		}
+		if err = c.Cancel(ctx, job.ID); err != nil {
+			return cancelled, err
+		}
+		cancelled = append(cancelled, job.ID)
+	}
+	return cancelled, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, wantStatus int) ([]byte, error) {
+	request, err := http.NewRequest(method, strings.TrimSuffix(c.URL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.Username != "" {
+		request.SetBasicAuth(c.Username, c.Password)
+	}
+	response, err := c.HTTP.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != wantStatus {
+		return nil, fmt.Errorf("Flink %s %s answered %s: %s", method, path, response.Status, strings.TrimSpace(string(content)))
+	}
+	return content, nil
+}
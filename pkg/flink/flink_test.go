@@ -0,0 +1,65 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+
+package flink
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCancelJobs(t *testing.T) {
+	var cancelled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/jobs/overview":
+			fmt.Fprint(w, `{"jobs": [
+				{"jid": "a1", "name": "demo-flink-processor", "state": "RUNNING"},
+				{"jid": "b2", "name": "demo-flink-processor", "state": "CANCELED"},
+				{"jid": "c3", "name": "other-flink-processor", "state": "RUNNING"},
+				{"jid": "d4", "name": "demo-flink-processor", "state": "RESTARTING"}
+			]}`)
+		case r.Method == http.MethodPatch && r.URL.Query().Get("mode") == "cancel":
+			cancelled = append(cancelled, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{HTTP: server.Client(), URL: server.URL + "/", Username: "admin", Password: "secret"}
+	ids, err := client.CancelJobs(context.Background(), "demo-flink-processor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"a1", "d4"}) {
+		t.Errorf("CancelJobs() = %v, want [a1 d4]", ids)
+	}
+	if !reflect.DeepEqual(cancelled, []string{"/jobs/a1", "/jobs/d4"}) {
+		t.Errorf("cancel requests %v, want [/jobs/a1 /jobs/d4]", cancelled)
+	}
+
+	client.Password = "wrong"
+	if _, err = client.CancelJobs(context.Background(), "demo-flink-processor"); err == nil {
+		t.Error("CancelJobs() with wrong credentials did not fail")
+	}
+}
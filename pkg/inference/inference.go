@@ -0,0 +1,253 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+
+// Package inference sends sample invoices to a KFServing predictor, to check that a deployed model answers
+// with well-formed predictions.
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocarina/gocsv"
+
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/producer"
+)
+
+// Protocol is a KFServing data plane protocol
+type Protocol string
+
+const (
+	// ProtocolV1 is the TensorFlow Serving style protocol, served at /v1/models/<name>:predict
+	ProtocolV1 Protocol = "v1"
+	// ProtocolV2 is the KFServing V2 inference protocol, served at /v2/models/<name>/infer
+	ProtocolV2 Protocol = "v2"
+)
+
+// Invoice is a sample invoice scored by the model, which only reads its payment delay
+type Invoice struct {
+	ID       string
+	PayDelay int
+}
+
+// Result is the outcome of scoring the sample invoices
+type Result struct {
+	Protocol Protocol
+	Latency  time.Duration
+	// Scores holds the score of each invoice, in the order they were sent
+	Scores []float64
+}
+
+// Risks counts the invoices by the risk the Flink job derives from their scores
+func (r Result) Risks() map[string]int {
+	risks := map[string]int{}
+	for _, score := range r.Scores {
+		risks[Risk(score)]++
+	}
+	return risks
+}
+
+// Risk maps a score to a risk the way the Flink job does: High above 100, Medium above 50, otherwise Low
+func Risk(score float64) string {
+	switch rounded := math.Ceil(score); {
+	case rounded > 100:
+		return "High"
+	case rounded > 50:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
+
+// SampleInvoices reads up to count invoices from the sample data sent by the producer, picking invoices with
+// different payment delays so that the model is asked for a range of scores
+func SampleInvoices(path string, count int) ([]Invoice, error) {
+	data, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the sample data %s: %w", path, err)
+	}
+	defer data.Close()
+	samples := []*producer.BaiMessage{}
+	if err = gocsv.UnmarshalFile(data, &samples); err != nil {
+		return nil, fmt.Errorf("Failed to read the sample data %s: %w", path, err)
+	}
+
+	var invoices []Invoice
+	seen := map[int]bool{}
+	for _, sample := range samples {
+		payDelay, err := strconv.Atoi(sample.Pay_Delay)
+		if sample.Invoice_ID == "" || err != nil || seen[payDelay] {
+			continue
+		}
+		seen[payDelay] = true
+		invoices = append(invoices, Invoice{ID: sample.Invoice_ID, PayDelay: payDelay})
+		if len(invoices) == count {
+			break
+		}
+	}
+	if len(invoices) == 0 {
+		return nil, fmt.Errorf("No invoices with a Pay_Delay found in the sample data %s", path)
+	}
+	return invoices, nil
+}
+
+// ProtocolOf returns the protocol of a predictor endpoint from its path. Endpoints under /v2/ use the V2
+// protocol; any other, such as the usual /v1/models/<name>:predict, uses V1.
+func ProtocolOf(endpoint string) Protocol {
+	location, err := url.Parse(endpoint)
+	if err == nil && strings.HasPrefix(location.Path, "/v2/") {
+		return ProtocolV2
+	}
+	return ProtocolV1
+}
+
+type v1Request struct {
+	Instances []map[string]int `json:"instances"`
+}
+
+type v1Response struct {
+	Predictions []json.RawMessage `json:"predictions"`
+}
+
+type v2Tensor struct {
+	Name     string        `json:"name"`
+	Shape    []int         `json:"shape"`
+	Datatype string        `json:"datatype"`
+	Data     []json.Number `json:"data"`
+}
+
+type v2Request struct {
+	Inputs []v2Tensor `json:"inputs"`
+}
+
+type v2Response struct {
+	ModelName string     `json:"model_name"`
+	Outputs   []v2Tensor `json:"outputs"`
+}
+
+// Score sends the invoices to the predictor endpoint in one request and checks that the response holds
+// a numeric score for each of them
+func Score(ctx context.Context, client *http.Client, endpoint string, invoices []Invoice) (Result, error) {
+	result := Result{Protocol: ProtocolOf(endpoint)}
+	var request interface{}
+	switch result.Protocol {
+	case ProtocolV2:
+		input := v2Tensor{Name: "Pay_Delay", Shape: []int{len(invoices), 1}, Datatype: "INT32"}
+		for _, invoice := range invoices {
+			input.Data = append(input.Data, json.Number(strconv.Itoa(invoice.PayDelay)))
+		}
+		request = v2Request{Inputs: []v2Tensor{input}}
+	default:
+		instances := v1Request{}
+		for _, invoice := range invoices {
+			instances.Instances = append(instances.Instances, map[string]int{"Pay_Delay": invoice.PayDelay})
+		}
+		request = instances
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return result, err
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Accept", "application/json")
+	start := time.Now()
+	response, err := client.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	result.Latency = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("Predictor answered %s: %s", response.Status, strings.TrimSpace(string(content)))
+	}
+
+	if result.Protocol == ProtocolV2 {
+		result.Scores, err = v2Scores(content, len(invoices))
+	} else {
+		result.Scores, err = v1Scores(content, len(invoices))
+	}
+	return result, err
+}
+
+// v1Scores reads one prediction per invoice. A prediction is either a number, or a list whose first
+// element is the score, as the anomaly classifier returns.
+func v1Scores(content []byte, count int) ([]float64, error) {
+	response := v1Response{}
+	if err := json.Unmarshal(content, &response); err != nil {
+		return nil, fmt.Errorf("Malformed V1 response: %w", err)
+	}
+	if len(response.Predictions) != count {
+		return nil, fmt.Errorf("Malformed V1 response: %d predictions for %d instances", len(response.Predictions), count)
+	}
+	scores := make([]float64, count)
+	for i, prediction := range response.Predictions {
+		var score float64
+		if err := json.Unmarshal(prediction, &score); err == nil {
+			scores[i] = score
+			continue
+		}
+		var scoreList []float64
+		if err := json.Unmarshal(prediction, &scoreList); err != nil || len(scoreList) == 0 {
+			return nil, fmt.Errorf("Malformed V1 response: prediction %d is %s, not a score", i, prediction)
+		}
+		scores[i] = scoreList[0]
+	}
+	return scores, nil
+}
+
+// v2Scores reads the first output tensor, whose first dimension has one row per invoice. The first
+// element of each row is the score.
+func v2Scores(content []byte, count int) ([]float64, error) {
+	response := v2Response{}
+	if err := json.Unmarshal(content, &response); err != nil {
+		return nil, fmt.Errorf("Malformed V2 response: %w", err)
+	}
+	if len(response.Outputs) == 0 {
+		return nil, fmt.Errorf("Malformed V2 response: no outputs")
+	}
+	output := response.Outputs[0]
+	size := 1
+	for _, dimension := range output.Shape {
+		size *= dimension
+	}
+	if len(output.Shape) == 0 || output.Shape[0] != count || len(output.Data) != size {
+		return nil, fmt.Errorf("Malformed V2 response: output %s of shape %v holds %d values for %d inputs",
+			output.Name, output.Shape, len(output.Data), count)
+	}
+	rowSize := size / count
+	scores := make([]float64, count)
+	for i := range scores {
+		score, err := output.Data[i*rowSize].Float64()
+		if err != nil {
+			return nil, fmt.Errorf("Malformed V2 response: output %s value %s is not a number", output.Name, output.Data[i*rowSize])
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
@@ -0,0 +1,106 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSampleInvoices(t *testing.T) {
+	invoices, err := SampleInvoices("../producer/sample.csv", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 5 {
+		t.Fatalf("got %d invoices, want 5", len(invoices))
+	}
+	seen := map[int]bool{}
+	for _, invoice := range invoices {
+		if invoice.ID == "" || seen[invoice.PayDelay] {
+			t.Errorf("unexpected invoice %+v in %+v", invoice, invoices)
+		}
+		seen[invoice.PayDelay] = true
+	}
+}
+
+// standIn serves a predictor that answers with the given body, recording the request it received
+func standIn(t *testing.T, body string, request *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			t.Errorf("malformed request: %v", err)
+		}
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestScore(t *testing.T) {
+	invoices := []Invoice{{ID: "a", PayDelay: 3}, {ID: "b", PayDelay: 89}, {ID: "c", PayDelay: 169}}
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		protocol Protocol
+		scores   []float64
+		wantErr  bool
+	}{
+		{"v1", "/v1/models/anomaly-classifier:predict", `{"predictions": [[2.5], [60], [140.2]]}`, ProtocolV1, []float64{2.5, 60, 140.2}, false},
+		{"v1 scalar predictions", "/v1/models/anomaly-classifier:predict", `{"predictions": [2.5, 60, 140.2]}`, ProtocolV1, []float64{2.5, 60, 140.2}, false},
+		{"v1 missing prediction", "/v1/models/anomaly-classifier:predict", `{"predictions": [[2.5], [60]]}`, ProtocolV1, nil, true},
+		{"v1 not a score", "/v1/models/anomaly-classifier:predict", `{"predictions": [["low"], [60], [140]]}`, ProtocolV1, nil, true},
+		{"v1 not json", "/v1/models/anomaly-classifier:predict", `<html>Bad Gateway</html>`, ProtocolV1, nil, true},
+		{"v2", "/v2/models/anomaly-classifier/infer", `{"model_name": "anomaly-classifier", "outputs": [{"name": "risk", "shape": [3, 1], "datatype": "FP32", "data": [2.5, 60, 140.2]}]}`, ProtocolV2, []float64{2.5, 60, 140.2}, false},
+		{"v2 wrong shape", "/v2/models/anomaly-classifier/infer", `{"model_name": "anomaly-classifier", "outputs": [{"name": "risk", "shape": [2, 1], "datatype": "FP32", "data": [2.5, 60]}]}`, ProtocolV2, nil, true},
+		{"v2 no outputs", "/v2/models/anomaly-classifier/infer", `{"model_name": "anomaly-classifier", "outputs": []}`, ProtocolV2, nil, true},
+	}
+	for _, tt := range tests {
+		request := map[string]interface{}{}
+		server := standIn(t, tt.body, &request)
+		result, err := Score(context.Background(), server.Client(), server.URL+tt.path, invoices)
+		server.Close()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Score() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if result.Protocol != tt.protocol {
+			t.Errorf("%s: protocol = %s, want %s", tt.name, result.Protocol, tt.protocol)
+		}
+		if _, ok := request["instances"]; ok != (tt.protocol == ProtocolV1) {
+			t.Errorf("%s: sent %v for protocol %s", tt.name, request, tt.protocol)
+		}
+		if !tt.wantErr && fmt.Sprint(result.Scores) != fmt.Sprint(tt.scores) {
+			t.Errorf("%s: scores = %v, want %v", tt.name, result.Scores, tt.scores)
+		}
+	}
+}
+
+func TestScoreError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	_, err := Score(context.Background(), server.Client(), server.URL+"/v1/models/anomaly-classifier:predict", []Invoice{{ID: "a", PayDelay: 3}})
+	if err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Errorf("Score() error = %v, want the predictor's answer", err)
+	}
+}
+
+func TestRisks(t *testing.T) {
+	risks := Result{Scores: []float64{2.5, 50.2, 100, 100.1}}.Risks()
+	if risks["Low"] != 1 || risks["Medium"] != 2 || risks["High"] != 1 {
+		t.Errorf("unexpected risks %v", risks)
+	}
+}
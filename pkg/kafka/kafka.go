@@ -0,0 +1,79 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+package kafka
+
+import (
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"hash"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/config"
+)
+
+// NewConfig returns the client settings to connect to Kafka over TLS as the SCRAM user of the IAFDemo
+func NewConfig(cfg *config.Config) *sarama.Config {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_3_0_0
+	config.Producer.RequiredAcks = sarama.WaitForAll // Wait for all in-sync replicas to ack the message
+	config.Producer.Retry.Max = 10                   // Retry up to 10 times to produce the message
+	config.Producer.Return.Successes = true
+
+	tlsConfig := &tls.Config{}
+	if len(cfg.KafkaCaCertPem) > 0 {
+		certPool := x509.NewCertPool()
+		certPool.AppendCertsFromPEM([]byte(cfg.KafkaCaCertPem))
+		tlsConfig = &tls.Config{RootCAs: certPool}
+	}
+
+	config.Net.TLS.Enable = true
+	config.Net.TLS.Config = tlsConfig
+
+	config.Net.SASL.Enable = true
+	config.Net.SASL.User = cfg.KafkaUsername
+	config.Net.SASL.Password = cfg.KafkaPassword
+	config.Net.SASL.SCRAMClientGeneratorFunc = scramClientGeneratorFunc
+	config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+
+	return config
+}
+
+func scramClientGeneratorFunc() sarama.SCRAMClient {
+	var SHA512 scram.HashGeneratorFcn = func() hash.Hash { return sha512.New() }
+	return &XDGSCRAMClient{HashGeneratorFcn: SHA512}
+}
+
+// See https://github.com/Shopify/sarama/blob/ceadf4f6b74eb2ca0b6108fd96778032b0fa404f/examples/sasl_scram_client/scram_client.go
+type XDGSCRAMClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+}
+
+func (x *XDGSCRAMClient) Begin(userName, password, authzID string) (err error) {
+	x.Client, err = x.HashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	x.ClientConversation = x.Client.NewConversation()
+	return nil
+}
+
+func (x *XDGSCRAMClient) Step(challenge string) (response string, err error) {
+	response, err = x.ClientConversation.Step(challenge)
+	return
+}
+
+func (x *XDGSCRAMClient) Done() bool {
+	return x.ClientConversation.Done()
+}
@@ -12,6 +12,7 @@ package operator
 import (
 	"flag"
 	"os"
+	"strings"
 
 	routev1 "github.com/openshift/api/route/v1"
 	aiv1alpha1 "github.ibm.com/automation-base-pak/abp-ai-operator/api/v1alpha1"
@@ -19,6 +20,7 @@ import (
 	kafkav1beta1 "github.ibm.com/automation-base-pak/abp-base-operator/api/v1beta1kafka"
 	corev1beta1 "github.ibm.com/automation-base-pak/abp-core-operator/api/v1beta1"
 	democartridgev1 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v1"
+	democartridgev2 "github.ibm.com/automation-base-pak/abp-demo-cartridge/api/v2"
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/controllers"
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/config"
 	epv1alpha1 "github.ibm.com/automation-base-pak/abp-eventprocessing/api/v1alpha1"
@@ -27,8 +29,11 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	knkafkachannel "knative.dev/eventing-contrib/kafka/channel/pkg/apis/messaging/v1alpha1"
 	knkafkasource "knative.dev/eventing-contrib/kafka/source/pkg/apis/sources/v1alpha1"
+	kneventing "knative.dev/eventing/pkg/apis/eventing/v1"
 	knmessaging "knative.dev/eventing/pkg/apis/messaging/v1"
+	knmessagingv1beta1 "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	// +kubebuilder:scaffold:imports
@@ -43,6 +48,7 @@ var (
 func Start(cfg *config.Config) {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(democartridgev1.AddToScheme(scheme))
+	utilruntime.Must(democartridgev2.AddToScheme(scheme))
 	utilruntime.Must(corev1beta1.AddToScheme(scheme))
 	utilruntime.Must(basev1beta1.AddToScheme(scheme))
 	utilruntime.Must(kafkav1beta1.AddToScheme(scheme))
@@ -51,7 +57,10 @@ func Start(cfg *config.Config) {
 	utilruntime.Must(epv1beta1.AddToScheme(scheme))
 	utilruntime.Must(routev1.AddToScheme(scheme))
 	utilruntime.Must(knkafkasource.AddToScheme(scheme))
+	utilruntime.Must(knkafkachannel.AddToScheme(scheme))
+	utilruntime.Must(kneventing.AddToScheme(scheme))
 	utilruntime.Must(knmessaging.AddToScheme(scheme))
+	utilruntime.Must(knmessagingv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 
 	var metricsAddr string
@@ -77,14 +86,29 @@ func Start(cfg *config.Config) {
 	}
 
 	if err = (&controllers.IAFDemoReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("IAFDemo"),
-		Scheme: mgr.GetScheme(),
-		Cfg:    cfg,
+		Client:     mgr.GetClient(),
+		Log:        ctrl.Log.WithName("controllers").WithName("IAFDemo"),
+		Scheme:     mgr.GetScheme(),
+		Cfg:        cfg,
+		Recorder:   mgr.GetEventRecorderFor("iafdemo-controller"),
+		RESTMapper: mgr.GetRESTMapper(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IAFDemo")
 		os.Exit(1)
 	}
+
+	// The webhooks need serving certificates, which OLM provides in-cluster.
+	// Set ENABLE_WEBHOOKS=false to run the operator locally without them.
+	if !strings.EqualFold(cfg.EnableWebhooks, "false") {
+		if err = (&democartridgev1.IAFDemo{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "IAFDemo", "version", "v1")
+			os.Exit(1)
+		}
+		if err = (&democartridgev2.IAFDemo{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "IAFDemo", "version", "v2")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
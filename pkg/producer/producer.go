@@ -11,10 +11,6 @@ package producer
 
 import (
 	"context"
-	"crypto/sha512"
-	"crypto/tls"
-	"crypto/x509"
-	"hash"
 	"log"
 	"math"
 	"os"
@@ -28,9 +24,9 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/gocarina/gocsv"
 	"github.com/google/uuid"
-	"github.com/xdg/scram"
 
 	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/config"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/kafka"
 )
 
 const (
@@ -41,18 +37,10 @@ const (
 func Start(cfg *config.Config) {
 	log.Println("Starting Producer Service")
 	brokers := strings.Split(cfg.BootstrapServers, ",")
-	var messagesPerGroup, secondsToPause, sequenceRepititions int
-	var err error = nil
-	if messagesPerGroup, err = strconv.Atoi(cfg.MessagesPerGroup); err != nil {
-		messagesPerGroup = math.MaxInt32
-	}
-	if secondsToPause, err = strconv.Atoi(cfg.SecondsToPause); err != nil {
-		secondsToPause = 0
-	}
-	if sequenceRepititions, err = strconv.Atoi(cfg.SequenceRepititions); err != nil {
-		sequenceRepititions = 1
-	}
-	config := newConfig(cfg)
+	messagesPerGroup := parseCount("MESSAGES_PER_GROUP", cfg.MessagesPerGroup, math.MaxInt32)
+	secondsToPause := parseCount("SECONDS_TO_PAUSE", cfg.SecondsToPause, 0)
+	sequenceRepititions := parseCount("SEQUENCE_REPITITIONS", cfg.SequenceRepititions, 1)
+	config := kafka.NewConfig(cfg)
 
 	logFile, err := os.OpenFile("/var/log/demoproducer.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
 	if err != nil {
@@ -71,9 +59,24 @@ func Start(cfg *config.Config) {
 		log.Fatalf("failed to create client, %v", err)
 	}
 
+	// Records that cannot be parsed go to the dead-letter topic, if there is one, instead of stopping the producer
+	var deadLetter cloudevents.Client
+	if cfg.DeadLetterTopic != "" {
+		deadLetterSender, err := kafka_sarama.NewSender(brokers, config, cfg.DeadLetterTopic)
+		if err != nil {
+			log.Fatalf("failed to create dead-letter protocol: %s", err.Error())
+		}
+		defer deadLetterSender.Close(context.Background())
+
+		deadLetter, err = cloudevents.NewClient(deadLetterSender, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+		if err != nil {
+			log.Fatalf("failed to create dead-letter client, %v", err)
+		}
+	}
+
 	// Send sequences of events to Kafka
 	for i := 0; i != sequenceRepititions; i++ {
-		sendSequence(c, messagesPerGroup, secondsToPause)
+		sendSequence(c, deadLetter, messagesPerGroup, secondsToPause)
 		log.Println("Sequence complete.")
 	}
 
@@ -83,7 +86,21 @@ func Start(cfg *config.Config) {
 	wg.Wait()
 }
 
-func sendSequence(c cloudevents.Client, messagesPerGroup int, secondsToPause int) {
+// parseCount reads an optional integer setting, returning defaultValue when it is not set or not an integer,
+// so that a bad value does not keep the producer from starting
+func parseCount(name, value string, defaultValue int) int {
+	if value == "" {
+		return defaultValue
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("%s %q is not an integer, using %d instead: %v", name, value, defaultValue, err)
+		return defaultValue
+	}
+	return count
+}
+
+func sendSequence(c cloudevents.Client, deadLetter cloudevents.Client, messagesPerGroup int, secondsToPause int) {
 	data, err := os.Open("sample.csv")
 	if err != nil {
 		log.Fatal(err)
@@ -102,7 +119,12 @@ func sendSequence(c cloudevents.Client, messagesPerGroup int, secondsToPause int
 		// Get the time of the event for inclusion in the CloudEvent
 		t, err := time.Parse(baiTimeFormat, s.DateTime)
 		if err != nil {
-			log.Fatal(err)
+			if deadLetter == nil {
+				log.Fatal(err)
+			}
+			log.Printf("malformed DateTime %q, sending the record to the dead-letter topic: %v", s.DateTime, err)
+			sendMessage(deadLetter, "bai.events.malformed", time.Now(), s)
+			continue
 		}
 
 		// Ensure a consistent format inside the actual message:
@@ -174,59 +196,3 @@ func sendMessage(c cloudevents.Client, evType string, t time.Time, data interfac
 		log.Printf("sent: %s, accepted: %t", e.ID(), cloudevents.IsACK(result))
 	}
 }
-
-func newConfig(cfg *config.Config) *sarama.Config {
-	config := sarama.NewConfig()
-	config.Version = sarama.V2_3_0_0
-	config.Producer.RequiredAcks = sarama.WaitForAll // Wait for all in-sync replicas to ack the message
-	config.Producer.Retry.Max = 10                   // Retry up to 10 times to produce the message
-	config.Producer.Return.Successes = true
-
-	tlsConfig := &tls.Config{}
-	if len(cfg.KafkaCaCertPem) > 0 {
-		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM([]byte(cfg.KafkaCaCertPem))
-		tlsConfig = &tls.Config{RootCAs: certPool}
-	}
-
-	config.Net.TLS.Enable = true
-	config.Net.TLS.Config = tlsConfig
-
-	config.Net.SASL.Enable = true
-	config.Net.SASL.User = cfg.KafkaUsername
-	config.Net.SASL.Password = cfg.KafkaPassword
-	config.Net.SASL.SCRAMClientGeneratorFunc = scramClientGeneratorFunc
-	config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
-
-	return config
-}
-
-func scramClientGeneratorFunc() sarama.SCRAMClient {
-	var SHA512 scram.HashGeneratorFcn = func() hash.Hash { return sha512.New() }
-	return &XDGSCRAMClient{HashGeneratorFcn: SHA512}
-}
-
-// See https://github.com/Shopify/sarama/blob/ceadf4f6b74eb2ca0b6108fd96778032b0fa404f/examples/sasl_scram_client/scram_client.go
-type XDGSCRAMClient struct {
-	*scram.Client
-	*scram.ClientConversation
-	scram.HashGeneratorFcn
-}
-
-func (x *XDGSCRAMClient) Begin(userName, password, authzID string) (err error) {
-	x.Client, err = x.HashGeneratorFcn.NewClient(userName, password, authzID)
-	if err != nil {
-		return err
-	}
-	x.ClientConversation = x.Client.NewConversation()
-	return nil
-}
-
-func (x *XDGSCRAMClient) Step(challenge string) (response string, err error) {
-	response, err = x.ClientConversation.Step(challenge)
-	return
-}
-
-func (x *XDGSCRAMClient) Done() bool {
-	return x.ClientConversation.Done()
-}
@@ -13,13 +13,43 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httputil"
+	"strings"
+
+	"github.com/Shopify/sarama"
+
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/config"
+	"github.ibm.com/automation-base-pak/abp-demo-cartridge/pkg/kafka"
 )
 
 const (
 	Port = 8080
 )
 
-func Start() {
+func Start(cfg *config.Config) {
+	// Keep the latest malformed events and high-risk alerts, so they can be inspected apart from the anomalies
+	topics := map[string]string{"/deadletter": cfg.DeadLetterTopic, "/alerts": cfg.AlertsTopic}
+	var consumer sarama.Consumer
+	for path, topic := range topics {
+		if topic == "" {
+			continue
+		}
+		if consumer == nil {
+			var err error
+			if consumer, err = sarama.NewConsumer(strings.Split(cfg.BootstrapServers, ","), kafka.NewConfig(cfg)); err != nil {
+				fmt.Println("Unable to connect to Kafka, so the dead-letter and alerts topics cannot be inspected:", err)
+				break
+			}
+			defer consumer.Close()
+		}
+		events := &recentEvents{topic: topic}
+		if err := events.consume(consumer); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		http.Handle(path, events)
+		fmt.Println("Listing the latest events of Kafka topic", topic, "at", path)
+	}
+
 	http.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		// DumpRequest will put the request information into readable plain-text
 		reqBytes, err := httputil.DumpRequest(req, true)
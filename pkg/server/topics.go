@@ -0,0 +1,97 @@
+// ------------------------------------------------------ {COPYRIGHT-TOP} ---
+// Licensed Materials - Property of IBM
+// 5900-AEO
+//
+// Copyright IBM Corp. 2020, 2021. All Rights Reserved.
+//
+// US Government Users Restricted Rights - Use, duplication, or
+// disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+// ------------------------------------------------------ {COPYRIGHT-END} ---
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// maxRecentEvents is how many of the latest events of each topic the server keeps for inspection
+const maxRecentEvents = 100
+
+// Event is a record read from a Kafka topic
+type Event struct {
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Timestamp time.Time         `json:"timestamp"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Value     string            `json:"value"`
+}
+
+// recentEvents holds the latest events read from a Kafka topic, oldest first
+type recentEvents struct {
+	topic  string
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recentEvents) add(message *sarama.ConsumerMessage) {
+	event := Event{
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Timestamp: message.Timestamp,
+		Key:       string(message.Key),
+		Value:     string(message.Value),
+	}
+	for _, header := range message.Headers {
+		if event.Headers == nil {
+			event.Headers = map[string]string{}
+		}
+		event.Headers[string(header.Key)] = string(header.Value)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	if len(r.events) > maxRecentEvents {
+		r.events = r.events[len(r.events)-maxRecentEvents:]
+	}
+}
+
+// consume reads every partition of the topic from the oldest retained record, keeping the latest events
+func (r *recentEvents) consume(consumer sarama.Consumer) error {
+	partitions, err := consumer.Partitions(r.topic)
+	if err != nil {
+		return fmt.Errorf("unable to list the partitions of Kafka topic %s: %w", r.topic, err)
+	}
+	for _, partition := range partitions {
+		partitionConsumer, err := consumer.ConsumePartition(r.topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return fmt.Errorf("unable to read partition %d of Kafka topic %s: %w", partition, r.topic, err)
+		}
+		go func() {
+			for message := range partitionConsumer.Messages() {
+				r.add(message)
+			}
+		}()
+	}
+	return nil
+}
+
+// ServeHTTP lists the latest events of the topic as JSON
+func (r *recentEvents) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	body, err := json.Marshal(r.events)
+	r.mu.Unlock()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintln(w, "Unable to list events")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}